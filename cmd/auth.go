@@ -13,7 +13,12 @@ func getAuthContext(kubeconfig string) (*auth.AuthContext, error) {
 	if err != nil {
 		return nil, err
 	}
-	authContext, err := auth.GetAuthContext(kubeconfig, formatContextName(cloudContext, cs.CurrentContext))
+	return getAuthContextOf(kubeconfig, cs.CurrentContext)
+}
+
+// getAuthContextOf returns the auth context of the given cloudctl context
+func getAuthContextOf(kubeconfig, ctxName string) (*auth.AuthContext, error) {
+	authContext, err := auth.GetAuthContext(kubeconfig, formatContextName(cloudContext, ctxName))
 	if err != nil {
 		return nil, err
 	}
@@ -2,15 +2,20 @@ package cmd
 
 import (
 	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"net"
 	"os"
-	"os/exec"
+	"os/signal"
 	"path"
+	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -22,8 +27,21 @@ import (
 	"github.com/fi-ts/cloudctl/cmd/output"
 
 	"github.com/Masterminds/semver"
+	"github.com/fatih/color"
+	"github.com/metal-stack/metal-lib/auth"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	gossh "golang.org/x/crypto/ssh"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	clusterWaitForSucceeded = "succeeded"
+	clusterWaitForDeleted   = "deleted"
+	clusterWaitInterval     = 5 * time.Second
+
+	maintenanceTimezoneDefault = "Europe/Berlin"
 )
 
 var (
@@ -35,8 +53,21 @@ var (
 	clusterCreateCmd = &cobra.Command{
 		Use:   "create",
 		Short: "create a cluster",
+		Long: `create a cluster, the values can be taken from a cluster template of the configuration with --cluster-template, see cluster template.
+values given by flags take precedence over the values of the template.`,
+		Example: `create a cluster with the values of a cluster template:
+# cloudctl cluster create --cluster-template prod-ha --name banking --project <project UID>`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return clusterCreate(cmd.Flags())
+		},
+		PreRun: bindPFlags,
+	}
+
+	clusterApplyCmd = &cobra.Command{
+		Use:   "apply",
+		Short: "create/update a cluster",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return clusterCreate()
+			return clusterApply()
 		},
 		PreRun: bindPFlags,
 	}
@@ -95,8 +126,11 @@ var (
 	}
 
 	clusterReconcileCmd = &cobra.Command{
-		Use:   "reconcile <uid>",
+		Use:   "reconcile [<uid>]",
 		Short: "trigger cluster reconciliation",
+		Long:  "trigger the reconciliation of a cluster, or of all clusters matching the selector given by --project, --tenant, --partition and --selector.",
+		Example: `retry the reconciliation of all clusters of a partition:
+# cloudctl cluster reconcile --partition nbg-w8101 --retry`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return reconcileCluster(args)
 		},
@@ -109,8 +143,10 @@ var (
 		PreRun: bindPFlags,
 	}
 	clusterUpdateCmd = &cobra.Command{
-		Use:   "update <uid>",
+		Use:   "update [<uid>]",
 		Short: "update a cluster",
+		Long: `update a cluster, or all clusters matching the selector given by --project, --tenant, --partition and --selector.
+Multiple clusters can only be updated with --version, --autoupdate-kubernetes, --autoupdate-machineimages, --maintenance-begin/end/timezone, --firewallcontroller and --purpose.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return updateCluster(args)
 		},
@@ -122,6 +158,34 @@ var (
 		},
 		PreRun: bindPFlags,
 	}
+	clusterWaitCmd = &cobra.Command{
+		Use:   "wait <uid>",
+		Short: "wait until the last operation of a cluster succeeded or the cluster is deleted",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return clusterWait(args)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return clusterListCompletion()
+		},
+		PreRun: bindPFlags,
+	}
+	clusterEditCmd = &cobra.Command{
+		Use:   "edit <uid>",
+		Short: "edit a cluster",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return clusterEdit(args)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return clusterListCompletion()
+		},
+		PreRun: bindPFlags,
+	}
 	clusterInputsCmd = &cobra.Command{
 		Use:   "inputs",
 		Short: "get possible cluster inputs like k8s versions, etc.",
@@ -130,6 +194,125 @@ var (
 		},
 		PreRun: bindPFlags,
 	}
+	clusterExportCmd = &cobra.Command{
+		Use:   "export <uid>",
+		Short: "export a cluster as create request",
+		Long:  "export a cluster as create request in yaml format, which can be used with cluster apply -f to create a cluster with the same configuration.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return clusterExport(args)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return clusterListCompletion()
+		},
+		PreRun: bindPFlags,
+	}
+	clusterCloneCmd = &cobra.Command{
+		Use:   "clone <uid>",
+		Short: "create a new cluster with the configuration of an existing cluster",
+		Long: `create a new cluster with the configuration of an existing cluster.
+static egress ips are not cloned, they can only be used by one cluster. external networks are specific to partition and project,
+they are only cloned if the new cluster is in the same partition and project, otherwise they can be given with --external-networks.`,
+		Example: `clone a cluster into another partition:
+# cloudctl cluster clone 9c3b7f0e-... --name banking-2 --partition nbg-w8101 --external-networks internet`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return clusterClone(args)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return clusterListCompletion()
+		},
+		PreRun: bindPFlags,
+	}
+	clusterEstimateCmd = &cobra.Command{
+		Use:   "estimate",
+		Short: "estimate the monthly costs of a cluster",
+		Long: `estimate the monthly costs of a cluster from its machine types, the autoscaling of its worker groups and the prices costs-cpu-hour, costs-memory-gi-hour and costs-storage-gi-hour of the configuration.
+the resources of the machine types must be configured in the configuration, e.g.:
+
+machine-types:
+  c1-xlarge-x86:
+    cpu: 32
+    memory-gi: 256
+    storage-gi: 1920`,
+		Example: `# cloudctl cluster estimate --firewalltype c1-xlarge-x86 --machinetype c1-xlarge-x86 --minsize 2 --maxsize 5`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return clusterEstimate()
+		},
+		PreRun: bindPFlags,
+	}
+	clusterTemplateCmd = &cobra.Command{
+		Use:     "template",
+		Aliases: []string{"templates"},
+		Short:   "inspect the cluster templates of the configuration",
+		Long: `cluster templates are partial create requests in the configuration which can be used with cluster create --cluster-template.
+templates can be defined globally or per context, templates of the current context take precedence over global templates with the same name.
+the fields are the same as in the output of cluster export, e.g.:
+
+cluster-templates:
+  small-dev:
+    purpose: development
+    firewallsize: c1-xlarge-x86
+    workers:
+      - machinetype: c1-xlarge-x86
+        minimum: 1
+        maximum: 2
+contexts:
+  prod:
+    url: https://api.metal-stack.io/cloud
+    cluster-templates:
+      prod-ha:
+        partitionid: nbg-w8101
+        purpose: production
+        firewallsize: c1-xlarge-x86
+        workers:
+          - name: group-0
+            machinetype: c1-xlarge-x86
+            minimum: 3
+            maximum: 6`,
+	}
+	clusterTemplateListCmd = &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "list the cluster templates of the configuration",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return clusterTemplateList()
+		},
+		PreRun: bindPFlags,
+	}
+	clusterTemplateShowCmd = &cobra.Command{
+		Use:   "show <name>",
+		Short: "show a cluster template of the configuration as create request",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return clusterTemplateShow(args)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return clusterTemplateListCompletion()
+		},
+		PreRun: bindPFlags,
+	}
+	clusterUpgradePlanCmd = &cobra.Command{
+		Use:   "upgrade-plan [<uid>]",
+		Short: "show how to upgrade clusters to the latest kubernetes version",
+		Long:  "show the next kubernetes versions clusters can be updated to and the date when the support of their current version ends. Without uid the clusters of the given project or all clusters are planned.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return clusterUpgradePlan(args)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return clusterListCompletion()
+		},
+		PreRun: bindPFlags,
+	}
 	clusterMachineCmd = &cobra.Command{
 		Use:     "machine",
 		Aliases: []string{"machines"},
@@ -150,6 +333,55 @@ var (
 		},
 		PreRun: bindPFlags,
 	}
+	clusterWorkerGroupCmd = &cobra.Command{
+		Use:     "workergroup",
+		Aliases: []string{"workergroups"},
+		Short:   "manage worker groups of the cluster",
+	}
+	clusterWorkerGroupListCmd = &cobra.Command{
+		Use:     "ls <clusterid>",
+		Aliases: []string{"list"},
+		Short:   "list worker groups of the cluster",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return clusterWorkerGroupList(args)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return clusterListCompletion()
+		},
+		PreRun: bindPFlags,
+	}
+	clusterWorkerGroupAddCmd = &cobra.Command{
+		Use:   "add <clusterid>",
+		Short: "add a worker group to the cluster",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return clusterWorkerGroupAdd(args)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return clusterListCompletion()
+		},
+		PreRun: bindPFlags,
+	}
+	clusterWorkerGroupRemoveCmd = &cobra.Command{
+		Use:     "remove <clusterid>",
+		Aliases: []string{"rm", "delete"},
+		Short:   "remove a worker group from the cluster",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return clusterWorkerGroupRemove(args)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return clusterListCompletion()
+		},
+		PreRun: bindPFlags,
+	}
 	clusterIssuesCmd = &cobra.Command{
 		Use:     "issues [<uid>]",
 		Aliases: []string{"problems", "warnings"},
@@ -166,10 +398,72 @@ var (
 		PreRun: bindPFlags,
 	}
 	clusterMachineSSHCmd = &cobra.Command{
-		Use:   "ssh <clusterid>",
+		Use:   "ssh <clusterid> [-- <command>]",
 		Short: "ssh access a machine/firewall of the cluster",
+		Long:  "opens a shell on a machine/firewall of the cluster, or executes the command given after -- on it.",
+		Example: `cloudctl cluster machine ssh <clusterid> --machineid <machineid>
+cloudctl cluster machine ssh <clusterid> --machineid <machineid> -- uptime`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var command []string
+			if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+				command = args[dash:]
+				args = args[:dash]
+			}
+			return clusterMachineSSH(args, command, false)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return clusterListCompletion()
+		},
+		PreRun: bindPFlags,
+	}
+	clusterExecCmd = &cobra.Command{
+		Use:     "exec <clusterid> -- <command>",
+		Short:   "execute a command on all machines/firewalls of the cluster",
+		Long:    "executes the command given after -- on the machines/firewalls of the cluster in parallel, every output line is prefixed with the name of the machine.",
+		Example: `cloudctl cluster exec <clusterid> --machines firewalls -- ip -br addr`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dash := cmd.ArgsLenAtDash()
+			if dash < 0 || dash == len(args) {
+				return fmt.Errorf("no command given, it must be passed after --")
+			}
+			return clusterExec(args[:dash], args[dash:])
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return clusterListCompletion()
+		},
+		PreRun: bindPFlags,
+	}
+	clusterPortForwardCmd = &cobra.Command{
+		Use:     "port-forward <clusterid> <localport>:<host>:<port>",
+		Short:   "forward a local port through a firewall of the cluster",
+		Long:    "opens a local port and tunnels every connection to it over ssh through the given firewall of the cluster to host:port, e.g. to reach services which are only reachable inside the private network of the cluster.",
+		Example: `cloudctl cluster port-forward <clusterid> --machineid <firewallid> 8080:10.0.0.5:80`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return clusterPortForward(args)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return clusterListCompletion()
+		},
+		PreRun: bindPFlags,
+	}
+	clusterSSHConfigCmd = &cobra.Command{
+		Use:   "ssh-config <clusterid>",
+		Short: "print ssh config entries for the machines/firewalls of the cluster",
+		Long: `writes the ssh key of the cluster to a file managed by cloudctl and prints host entries for the firewalls and machines of the cluster,
+which makes them accessible with ssh, scp and rsync by the name of the machine. Machines are reached with the firewall as jump host.`,
+		Example: `cloudctl cluster ssh-config <clusterid> --merge
+ssh shoot--abcdef--mycluster-firewall-1`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return clusterMachineSSH(args, false)
+			return clusterSSHConfig(args)
 		},
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 			if len(args) != 0 {
@@ -183,7 +477,7 @@ var (
 		Use:   "console <clusterid>",
 		Short: "console access a machine/firewall of the cluster",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return clusterMachineSSH(args, true)
+			return clusterMachineSSH(args, nil, true)
 		},
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 			if len(args) != 0 {
@@ -213,7 +507,7 @@ func init() {
 	clusterCreateCmd.Flags().String("name", "", "name of the cluster, max 10 characters. [required]")
 	clusterCreateCmd.Flags().String("description", "", "description of the cluster. [optional]")
 	clusterCreateCmd.Flags().String("project", "", "project where this cluster should belong to. [required]")
-	clusterCreateCmd.Flags().String("partition", "", "partition of the cluster. [required]")
+	clusterCreateCmd.Flags().String("partition", "", "partition of the cluster, required if not given by the template.")
 	clusterCreateCmd.Flags().String("purpose", "evaluation", "purpose of the cluster, can be one of production|development|evaluation. SLA is only given on production clusters. [optional]")
 	clusterCreateCmd.Flags().String("version", "", "kubernetes version of the cluster. defaults to latest available, check cluster inputs for possible values. [optional]")
 	clusterCreateCmd.Flags().String("machinetype", "", "machine type to use for the nodes. [optional]")
@@ -232,19 +526,21 @@ func init() {
 	clusterCreateCmd.Flags().BoolP("allowprivileged", "", false, "allow privileged containers the cluster.")
 	clusterCreateCmd.Flags().Duration("healthtimeout", 0, "period (e.g. \"24h\") after which an unhealthy node is declared failed and will be replaced. [optional]")
 	clusterCreateCmd.Flags().Duration("draintimeout", 0, "period (e.g. \"3h\") after which a draining node will be forcefully deleted. [optional]")
-
-	err := clusterCreateCmd.MarkFlagRequired("name")
-	if err != nil {
-		log.Fatal(err.Error())
-	}
-	err = clusterCreateCmd.MarkFlagRequired("project")
-	if err != nil {
-		log.Fatal(err.Error())
-	}
-	err = clusterCreateCmd.MarkFlagRequired("partition")
-	if err != nil {
-		log.Fatal(err.Error())
-	}
+	clusterCreateCmd.Flags().StringArray("worker", []string{}, `worker group of the cluster, can be given multiple times to create multiple worker groups.
+	must be in the form name=<name>,machinetype=<type>,min=<minsize>,max=<maxsize>,image=<name>-<version>,cri=<cri>,maxsurge=<maxsurge>,maxunavailable=<maxunavailable>,
+	only name is required, values which are not given are taken from the corresponding flags like --machinetype; e.g.:
+	--worker name=system,min=1,max=2 --worker name=compute,machinetype=s2-xlarge-x86,min=2,max=10,image=ubuntu-20.04 [optional]`)
+	clusterCreateCmd.Flags().String("maintenance-begin", "22:00", "begin of the daily maintenance time window of the cluster in the form hh:mm. [optional]")
+	clusterCreateCmd.Flags().String("maintenance-end", "23:30", "end of the daily maintenance time window of the cluster in the form hh:mm. [optional]")
+	clusterCreateCmd.Flags().String("maintenance-timezone", maintenanceTimezoneDefault, "IANA timezone (e.g. Europe/Berlin or UTC) of the maintenance time window. [optional]")
+	clusterCreateCmd.Flags().Bool("estimate", false, "print the estimated monthly costs of the cluster instead of creating it, see cluster estimate.")
+	clusterCreateCmd.Flags().Bool("interactive", false, "ask for the values of the cluster step by step with the possible choices, flags given are taken as defaults.")
+	clusterCreateCmd.Flags().String("cluster-template", "", "cluster template of the configuration to take the values from, values given by flags take precedence, see cluster template. [optional]")
+	addClusterWaitFlags(clusterCreateCmd)
+
+	clusterCreateCmd.RegisterFlagCompletionFunc("cluster-template", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return clusterTemplateListCompletion()
+	})
 	clusterCreateCmd.RegisterFlagCompletionFunc("project", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return projectListCompletion()
 	})
@@ -276,12 +572,31 @@ func init() {
 		return []string{"docker", "containerd"}, cobra.ShellCompDirectiveDefault
 	})
 
+	// Cluster apply --------------------------------------------------------------------
+	clusterApplyCmd.Flags().StringP("file", "f", "", `filename of the create or update request in yaml format, or - for stdin.
+	A document with an id updates the given cluster, a document without an id creates the cluster
+	or updates an existing cluster with the same name in the same project.
+	Example cluster update:
+
+	# cloudctl cluster describe 2e6f1e5f-3b53-4b6e-9f19-8ba8ee0d7d6b -o yaml > cluster1.yaml
+	# vi cluster1.yaml
+	## either via stdin
+	# cat cluster1.yaml | cloudctl cluster apply -f -
+	## or via file
+	# cloudctl cluster apply -f cluster1.yaml
+	`)
+	err := clusterApplyCmd.MarkFlagRequired("file")
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
 	// Cluster list --------------------------------------------------------------------
 	clusterListCmd.Flags().String("id", "", "show clusters of given id")
 	clusterListCmd.Flags().String("name", "", "show clusters of given name")
 	clusterListCmd.Flags().String("project", "", "show clusters of given project")
 	clusterListCmd.Flags().String("partition", "", "show clusters in partition")
 	clusterListCmd.Flags().String("tenant", "", "show clusters of given tenant")
+	addWatchFlags(clusterListCmd)
 	clusterListCmd.RegisterFlagCompletionFunc("project", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return projectListCompletion()
 	})
@@ -289,6 +604,14 @@ func init() {
 		return partitionListCompletion()
 	})
 
+	// Cluster kubeconfig --------------------------------------------------------------------
+	clusterKubeconfigCmd.Flags().Bool("merge", false, "merge the cluster, user and context into an existing kubeconfig instead of printing it.")
+	clusterKubeconfigCmd.Flags().String("kubeconfig-path", "", "path of the kubeconfig to merge into, defaults to $KUBECONFIG or ~/.kube/config. only used together with --merge.")
+	clusterKubeconfigCmd.Flags().Bool("set-current", false, "set the merged context as current context. only used together with --merge.")
+	clusterKubeconfigCmd.Flags().String("context-name", "", "name of the kubeconfig context, defaults to <user>@<cluster>.")
+	clusterKubeconfigCmd.Flags().String("output-file", "", "write the kubeconfig to the given file with 0600 permissions instead of printing it.")
+	clusterKubeconfigCmd.Flags().Bool("exec", false, "authenticate by calling cloudctl token as exec credential plugin instead of embedding the id token, which refreshes expired tokens automatically.")
+
 	// Cluster update --------------------------------------------------------------------
 	clusterUpdateCmd.Flags().String("workergroup", "", "the name of the worker group to apply updates to, only required when there are multiple worker groups.")
 	clusterUpdateCmd.Flags().Int32("minsize", 0, "minimal workers of the cluster.")
@@ -311,6 +634,13 @@ func init() {
 	clusterUpdateCmd.Flags().String("maxunavailable", "", "max number (e.g. 1) or percentage (e.g. 10%) of workers that can be unavailable during a update of the cluster.")
 	clusterUpdateCmd.Flags().BoolP("autoupdate-kubernetes", "", false, "enables automatic updates of the kubernetes patch version of the cluster")
 	clusterUpdateCmd.Flags().BoolP("autoupdate-machineimages", "", false, "enables automatic updates of the worker node images of the cluster, be aware that this deletes worker nodes!")
+	clusterUpdateCmd.Flags().String("maintenance-begin", "", "begin of the daily maintenance time window of the cluster in the form hh:mm, requires --maintenance-end.")
+	clusterUpdateCmd.Flags().String("maintenance-end", "", "end of the daily maintenance time window of the cluster in the form hh:mm, requires --maintenance-begin.")
+	clusterUpdateCmd.Flags().String("maintenance-timezone", maintenanceTimezoneDefault, "IANA timezone (e.g. Europe/Berlin or UTC) of the maintenance time window.")
+	clusterUpdateCmd.Flags().Bool("dry-run", false, "print the update request instead of sending it.")
+	clusterUpdateCmd.Flags().Bool("diff", false, "show the changes of the update compared to the current cluster and ask for confirmation before sending it.")
+	addClusterWaitFlags(clusterUpdateCmd)
+	addClusterSelectorFlags(clusterUpdateCmd)
 
 	clusterUpdateCmd.RegisterFlagCompletionFunc("version", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return versionListCompletion()
@@ -331,45 +661,184 @@ func init() {
 		return []string{"production", "development", "evaluation"}, cobra.ShellCompDirectiveDefault
 	})
 
-	clusterMachineSSHCmd.Flags().String("machineid", "", "machine to connect to.")
+	clusterMachineSSHCmd.Flags().String("machineid", "", "machine to connect to, workers are reached through the firewall of the cluster.")
 	clusterMachineSSHCmd.MarkFlagRequired("machineid")
 	clusterMachineSSHCmd.RegisterFlagCompletionFunc("machineid", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		// FIXME howto implement flag based completion for a already given clusterid
-		return clusterMachineListCompletion("123")
+		if len(args) == 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return clusterMachineListCompletion(args[0])
 	})
 	clusterMachineConsoleCmd.Flags().String("machineid", "", "machine to connect to.")
 	clusterMachineConsoleCmd.MarkFlagRequired("machineid")
 	clusterMachineConsoleCmd.RegisterFlagCompletionFunc("machineid", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		// FIXME howto implement flag based completion for a already given clusterid
-		return clusterMachineListCompletion("123")
+		if len(args) == 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return clusterMachineListCompletion(args[0])
+	})
+	clusterExecCmd.Flags().String("machines", "all", "machines to execute the command on, can be one of all|firewalls|workers.")
+	clusterExecCmd.Flags().Int("parallel", 10, "maximum number of machines the command is executed on at the same time.")
+	clusterExecCmd.RegisterFlagCompletionFunc("machines", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"all", "firewalls", "workers"}, cobra.ShellCompDirectiveNoFileComp
 	})
+
+	clusterPortForwardCmd.Flags().String("machineid", "", "firewall to forward the connections through.")
+	clusterPortForwardCmd.MarkFlagRequired("machineid")
+	clusterPortForwardCmd.RegisterFlagCompletionFunc("machineid", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return clusterFirewallListCompletion(args[0])
+	})
+
+	clusterSSHConfigCmd.Flags().Bool("merge", false, "merge the host entries at the beginning of the ssh config instead of printing them, existing entries of the cluster are replaced.")
+	clusterSSHConfigCmd.Flags().String("ssh-config-path", "", "path of the ssh config to merge into, defaults to ~/.ssh/config. only used together with --merge.")
+
 	clusterMachineCmd.AddCommand(clusterMachineListCmd)
 	clusterMachineCmd.AddCommand(clusterMachineSSHCmd)
 	clusterMachineCmd.AddCommand(clusterMachineConsoleCmd)
 
+	clusterWorkerGroupAddCmd.Flags().String("name", "", "name of the worker group. [required]")
+	clusterWorkerGroupAddCmd.Flags().String("machinetype", "", "machine type to use for the nodes, defaults to the machine type of the first worker group. [optional]")
+	clusterWorkerGroupAddCmd.Flags().String("machineimage", "", "machine image to use for the nodes, must be in the form of <name>-<version>, defaults to the machine image of the first worker group. [optional]")
+	clusterWorkerGroupAddCmd.Flags().String("cri", "", "container runtime to use, only docker|containerd supported as alternative actually, defaults to the runtime of the first worker group. [optional]")
+	clusterWorkerGroupAddCmd.Flags().Int32("minsize", 1, "minimal workers of the worker group.")
+	clusterWorkerGroupAddCmd.Flags().Int32("maxsize", 1, "maximal workers of the worker group.")
+	clusterWorkerGroupAddCmd.Flags().String("maxsurge", "1", "max number (e.g. 1) or percentage (e.g. 10%) of workers created during a update of the worker group.")
+	clusterWorkerGroupAddCmd.Flags().String("maxunavailable", "1", "max number (e.g. 1) or percentage (e.g. 10%) of workers that can be unavailable during a update of the worker group.")
+	err = clusterWorkerGroupAddCmd.MarkFlagRequired("name")
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	addClusterWaitFlags(clusterWorkerGroupAddCmd)
+	clusterWorkerGroupAddCmd.RegisterFlagCompletionFunc("machinetype", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return machineTypeListCompletion()
+	})
+	clusterWorkerGroupAddCmd.RegisterFlagCompletionFunc("machineimage", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return machineImageListCompletion()
+	})
+	clusterWorkerGroupAddCmd.RegisterFlagCompletionFunc("cri", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"docker", "containerd"}, cobra.ShellCompDirectiveDefault
+	})
+	clusterWorkerGroupRemoveCmd.Flags().String("name", "", "name of the worker group. [required]")
+	err = clusterWorkerGroupRemoveCmd.MarkFlagRequired("name")
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	addClusterWaitFlags(clusterWorkerGroupRemoveCmd)
+	clusterTemplateCmd.AddCommand(clusterTemplateListCmd)
+	clusterTemplateCmd.AddCommand(clusterTemplateShowCmd)
+
+	clusterWorkerGroupCmd.AddCommand(clusterWorkerGroupListCmd)
+	clusterWorkerGroupCmd.AddCommand(clusterWorkerGroupAddCmd)
+	clusterWorkerGroupCmd.AddCommand(clusterWorkerGroupRemoveCmd)
+
 	clusterReconcileCmd.Flags().Bool("retry", false, "Executes a cluster \"retry\" operation instead of regular \"reconcile\".")
 	clusterReconcileCmd.Flags().Bool("maintain", false, "Executes a cluster \"maintain\" operation instead of regular \"reconcile\".")
+	addClusterWaitFlags(clusterReconcileCmd)
+	addClusterSelectorFlags(clusterReconcileCmd)
+
+	addClusterWaitFlags(clusterDeleteCmd)
+
+	clusterInputsCmd.Flags().String("partition", "", "only show inputs for clusters in the given partition.")
+	clusterInputsCmd.Flags().StringSlice("kind", []string{}, "only show inputs of the given kinds, can be "+strings.Join(output.ClusterInputKinds, "|")+".")
+	clusterInputsCmd.RegisterFlagCompletionFunc("partition", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return partitionListCompletion()
+	})
+	clusterInputsCmd.RegisterFlagCompletionFunc("kind", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return output.ClusterInputKinds, cobra.ShellCompDirectiveNoFileComp
+	})
+
+	clusterEstimateCmd.Flags().String("machinetype", "", "machine type to use for the nodes.")
+	clusterEstimateCmd.Flags().String("firewalltype", "", "machine type to use for the firewall. [required]")
+	clusterEstimateCmd.Flags().Int32("minsize", 1, "minimal workers of the cluster.")
+	clusterEstimateCmd.Flags().Int32("maxsize", 1, "maximal workers of the cluster.")
+	clusterEstimateCmd.Flags().StringArray("worker", []string{}, `worker group of the cluster, can be given multiple times, see cluster create --worker; e.g.:
+	--worker name=system,min=1,max=2 --worker name=compute,machinetype=s2-xlarge-x86,min=2,max=10`)
+	err = clusterEstimateCmd.MarkFlagRequired("firewalltype")
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	clusterEstimateCmd.RegisterFlagCompletionFunc("machinetype", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return machineTypeListCompletion()
+	})
+	clusterEstimateCmd.RegisterFlagCompletionFunc("firewalltype", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return firewallTypeListCompletion()
+	})
+
+	clusterUpgradePlanCmd.Flags().String("project", "", "plan the clusters of the given project.")
+	clusterUpgradePlanCmd.Flags().Bool("machine-images", false, "plan updates of worker groups with expiring machine images as well.")
+	clusterUpgradePlanCmd.RegisterFlagCompletionFunc("project", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return projectListCompletion()
+	})
+
+	clusterCloneCmd.Flags().String("name", "", "name of the new cluster, max 10 characters. [required]")
+	clusterCloneCmd.Flags().String("project", "", "project of the new cluster, defaults to the project of the cloned cluster. [optional]")
+	clusterCloneCmd.Flags().String("partition", "", "partition of the new cluster, defaults to the partition of the cloned cluster. [optional]")
+	clusterCloneCmd.Flags().StringSlice("external-networks", []string{}, "external networks of the new cluster, defaults to the external networks of the cloned cluster if partition and project are the same. [optional]")
+	addClusterWaitFlags(clusterCloneCmd)
+	err = clusterCloneCmd.MarkFlagRequired("name")
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	clusterCloneCmd.RegisterFlagCompletionFunc("project", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return projectListCompletion()
+	})
+	clusterCloneCmd.RegisterFlagCompletionFunc("partition", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return partitionListCompletion()
+	})
+	clusterCloneCmd.RegisterFlagCompletionFunc("external-networks", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return networkListCompletion()
+	})
+
+	clusterWaitCmd.Flags().String("for", clusterWaitForSucceeded, "the condition to wait for, can be one of succeeded|deleted.")
+	clusterWaitCmd.Flags().Duration("timeout", 30*time.Minute, "period after which waiting is aborted with an error.")
+	clusterWaitCmd.RegisterFlagCompletionFunc("for", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{clusterWaitForSucceeded, clusterWaitForDeleted}, cobra.ShellCompDirectiveDefault
+	})
 
 	clusterIssuesCmd.Flags().String("id", "", "show clusters of given id")
 	clusterIssuesCmd.Flags().String("name", "", "show clusters of given name")
 	clusterIssuesCmd.Flags().String("project", "", "show clusters of given project")
 	clusterIssuesCmd.Flags().String("partition", "", "show clusters in partition")
 	clusterIssuesCmd.Flags().String("tenant", "", "show clusters of given tenant")
+	clusterIssuesCmd.Flags().Bool("exit-code", false, "exit with the highest severity of all issues: 0 without issues, 1 for warnings and 2 for critical issues.")
+	clusterIssuesCmd.Flags().String("monitoring-output", "", "print the issues for monitoring instead of a table, can be one of nagios|openmetrics. nagios always exits with the nagios plugin exit code, 3 (unknown) if the issues can not be determined.")
+	clusterIssuesCmd.RegisterFlagCompletionFunc("monitoring-output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"nagios", "openmetrics"}, cobra.ShellCompDirectiveNoFileComp
+	})
+
+	addWatchFlags(clusterLogsCmd)
 
 	clusterCmd.AddCommand(clusterCreateCmd)
+	clusterCmd.AddCommand(clusterApplyCmd)
 	clusterCmd.AddCommand(clusterListCmd)
 	clusterCmd.AddCommand(clusterKubeconfigCmd)
 	clusterCmd.AddCommand(clusterDeleteCmd)
 	clusterCmd.AddCommand(clusterDescribeCmd)
 	clusterCmd.AddCommand(clusterInputsCmd)
+	clusterCmd.AddCommand(clusterExportCmd)
+	clusterCmd.AddCommand(clusterCloneCmd)
+	clusterCmd.AddCommand(clusterUpgradePlanCmd)
+	clusterCmd.AddCommand(clusterEstimateCmd)
+	clusterCmd.AddCommand(clusterTemplateCmd)
 	clusterCmd.AddCommand(clusterReconcileCmd)
 	clusterCmd.AddCommand(clusterUpdateCmd)
+	clusterCmd.AddCommand(clusterEditCmd)
 	clusterCmd.AddCommand(clusterMachineCmd)
+	clusterCmd.AddCommand(clusterExecCmd)
+	clusterCmd.AddCommand(clusterPortForwardCmd)
+	clusterCmd.AddCommand(clusterSSHConfigCmd)
+	clusterCmd.AddCommand(clusterWorkerGroupCmd)
 	clusterCmd.AddCommand(clusterLogsCmd)
 	clusterCmd.AddCommand(clusterIssuesCmd)
+	clusterCmd.AddCommand(clusterWaitCmd)
 }
 
-func clusterCreate() error {
+// clusterCreateRequestFromFlags returns the create request given by the flags and the template,
+// together with the constraints it has to satisfy.
+func clusterCreateRequestFromFlags() (*models.V1ClusterCreateRequest, *models.V1ShootConstraints, error) {
 	name := viper.GetString("name")
 	desc := viper.GetString("description")
 	partition := viper.GetString("partition")
@@ -398,80 +867,65 @@ func clusterCreate() error {
 	// FIXME helper and validation
 	networks := viper.GetStringSlice("external-networks")
 	egress := viper.GetStringSlice("egress")
-	maintenanceBegin := "220000+0100"
-	maintenanceEnd := "233000+0100"
-
-	version := viper.GetString("version")
-	if version == "" {
-		request := cluster.NewListConstraintsParams()
-		constraints, err := cloud.Cluster.ListConstraints(request, nil)
-		if err != nil {
-			return err
-		}
-
-		availableVersions := constraints.Payload.KubernetesVersions
-		if len(availableVersions) == 0 {
-			log.Fatalf("no kubernetes versions available to deploy")
-		}
-
-		sortedVersions := make([]*semver.Version, len(availableVersions))
-		for i, r := range availableVersions {
-			v, err := semver.NewVersion(r)
-			if err != nil {
-				log.Fatalf("Error parsing version: %s", err)
-			}
+	maintenanceBegin, maintenanceEnd, err := helper.MaintenanceTimeWindow(viper.GetString("maintenance-begin"), viper.GetString("maintenance-end"), viper.GetString("maintenance-timezone"))
+	if err != nil {
+		return nil, nil, err
+	}
 
-			sortedVersions[i] = v
-		}
+	sc, err := clusterConstraints()
+	if err != nil {
+		return nil, nil, err
+	}
 
-		sort.Sort(semver.Collection(sortedVersions))
+	version := viper.GetString("version")
 
-		version = sortedVersions[len(sortedVersions)-1].String()
+	template, err := clusterTemplate(viper.GetString("cluster-template"))
+	if err != nil {
+		return nil, nil, err
 	}
 
-	machineImage := models.V1MachineImage{}
+	machineImage := &models.V1MachineImage{}
 	if machineImageAndVersion != "" {
-		machineImageParts := strings.Split(machineImageAndVersion, "-")
-		if len(machineImageParts) == 2 {
-			machineImage = models.V1MachineImage{
-				Name:    &machineImageParts[0],
-				Version: &machineImageParts[1],
-			}
-		} else {
-			log.Fatalf("given machineimage:%s is invalid must be in the form <name>-<version>", machineImageAndVersion)
+		var err error
+		machineImage, err = parseMachineImage(machineImageAndVersion)
+		if err != nil {
+			return nil, nil, err
 		}
 	}
 
 	labelMap, err := helper.LabelsToMap(labels)
 	if err != nil {
-		log.Fatal(err)
+		return nil, nil, err
 	}
 
-	switch cri {
-	case "containerd":
-	case "docker":
-	case "":
-	default:
-		log.Fatalf("provided cri:%s is not supported, only docker or containerd at the moment", cri)
+	defaultWorker := models.V1Worker{
+		Minimum:        &minsize,
+		Maximum:        &maxsize,
+		MaxSurge:       &maxsurge,
+		MaxUnavailable: &maxunavailable,
+		MachineType:    &machineType,
+		MachineImage:   machineImage,
+		CRI:            &cri,
+	}
+	if healthtimeout != 0 {
+		defaultWorker.HealthTimeout = int64(healthtimeout)
+	}
+	if draintimeout != 0 {
+		defaultWorker.DrainTimeout = int64(draintimeout)
+	}
+
+	workers, err := clusterTemplateWorkers(template, defaultWorker)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	scr := &models.V1ClusterCreateRequest{
-		ProjectID:   &project,
-		Name:        &name,
-		Labels:      labelMap,
-		Description: &desc,
-		Purpose:     &purpose,
-		Workers: []*models.V1Worker{
-			{
-				Minimum:        &minsize,
-				Maximum:        &maxsize,
-				MaxSurge:       &maxsurge,
-				MaxUnavailable: &maxunavailable,
-				MachineType:    &machineType,
-				MachineImage:   &machineImage,
-				CRI:            &cri,
-			},
-		},
+		ProjectID:                 &project,
+		Name:                      &name,
+		Labels:                    labelMap,
+		Description:               &desc,
+		Purpose:                   &purpose,
+		Workers:                   workers,
 		FirewallSize:              &firewallType,
 		FirewallImage:             &firewallImage,
 		FirewallControllerVersion: &firewallController,
@@ -489,29 +943,83 @@ func clusterCreate() error {
 		PartitionID:        &partition,
 	}
 
-	egressRules := makeEgressRules(egress)
+	egressRules, err := makeEgressRules(egress)
+	if err != nil {
+		return nil, nil, err
+	}
 	if len(egressRules) > 0 {
 		scr.EgressRules = egressRules
 	}
 
-	if healthtimeout != 0 {
-		scr.Workers[0].HealthTimeout = int64(healthtimeout)
+	if template != nil {
+		applyClusterTemplate(template, scr)
 	}
-
-	if draintimeout != 0 {
-		scr.Workers[0].DrainTimeout = int64(draintimeout)
+	if *scr.Kubernetes.Version == "" {
+		latest, err := latestKubernetesVersion(sc)
+		if err != nil {
+			return nil, nil, err
+		}
+		scr.Kubernetes.Version = &latest
 	}
 
-	request := cluster.NewCreateClusterParams()
-	request.SetBody(scr)
-	shoot, err := cloud.Cluster.CreateCluster(request, nil)
+	return scr, sc, nil
+}
+
+func clusterCreate(flags *pflag.FlagSet) error {
+	if viper.GetBool("interactive") {
+		return clusterCreateInteractive(flags)
+	}
+	scr, sc, err := clusterCreateRequestFromFlags()
 	if err != nil {
 		return err
 	}
-	return printer.Print(shoot.Payload)
-}
-
-func clusterList() error {
+	if viper.GetBool("estimate") {
+		return printClusterEstimate(scr.Workers, output.StrValue(scr.FirewallSize))
+	}
+	err = checkClusterCreateRequest(sc, scr)
+	if err != nil {
+		return err
+	}
+	return createCluster(scr)
+}
+
+// checkClusterCreateRequest checks that the required values are given and valid
+func checkClusterCreateRequest(sc *models.V1ShootConstraints, scr *models.V1ClusterCreateRequest) error {
+	if output.StrValue(scr.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if output.StrValue(scr.ProjectID) == "" {
+		return fmt.Errorf("project is required")
+	}
+	if output.StrValue(scr.PartitionID) == "" {
+		return fmt.Errorf("partition is required, either by --partition or by the template")
+	}
+	return validateClusterCreateRequest(sc, scr)
+}
+
+func createCluster(scr *models.V1ClusterCreateRequest) error {
+	request := cluster.NewCreateClusterParams()
+	request.SetBody(scr)
+	shoot, err := cloud.Cluster.CreateCluster(request, nil)
+	if err != nil {
+		return err
+	}
+	return printClusterOrWait(shoot.Payload, nil, clusterWaitForSucceeded)
+}
+
+func clusterList() error {
+	if viper.GetBool("watch") {
+		return clusterListWatch()
+	}
+	shoots, err := findClusters(false)
+	if err != nil {
+		return err
+	}
+	return printer.Print(shoots)
+}
+
+// findClusters returns the clusters matching the filter flags, with their machines if returnMachines is true
+func findClusters(returnMachines bool) ([]*models.V1ClusterResponse, error) {
 	id := viper.GetString("id")
 	name := viper.GetString("name")
 	tenant := viper.GetString("tenant")
@@ -539,20 +1047,168 @@ func clusterList() error {
 	}
 	if cfr != nil {
 		fcp := cluster.NewFindClustersParams()
+		if returnMachines {
+			fcp.SetReturnMachines(&returnMachines)
+		}
 		fcp.SetBody(cfr)
 		response, err := cloud.Cluster.FindClusters(fcp, nil)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		return printer.Print(response.Payload)
+		return response.Payload, nil
 	}
 
 	request := cluster.NewListClustersParams()
+	if returnMachines {
+		request.SetReturnMachines(&returnMachines)
+	}
 	shoots, err := cloud.Cluster.ListClusters(request, nil)
+	if err != nil {
+		return nil, err
+	}
+	return shoots.Payload, nil
+}
+
+// clusterListWatch lists the clusters and afterwards only the clusters which were added, changed or deleted
+func clusterListWatch() error {
+	interval, err := watchInterval()
+	if err != nil {
+		return err
+	}
+	// only the first listing has headers, changes are printed as additional rows
+	rowPrinter, err := output.NewPrinter(viper.GetString("output-format"), viper.GetString("order"), viper.GetString("template"), true)
+	if err != nil {
+		return err
+	}
+
+	var previous []*models.V1ClusterResponse
+	for first := true; ; first = false {
+		shoots, err := findClusters(false)
+		if err != nil {
+			return err
+		}
+		if first && printer.Type() == "table" {
+			err = printer.Print(shoots)
+			if err != nil {
+				return err
+			}
+		} else {
+			events := output.ClusterListEvents(previous, shoots)
+			if printer.Type() == "table" {
+				var changed []*models.V1ClusterResponse
+				for _, e := range events {
+					if e.Type == output.ClusterEventDeleted {
+						fmt.Printf("cluster %s (%s) deleted\n", output.StrValue(e.Cluster.Name), e.ClusterID)
+						continue
+					}
+					changed = append(changed, e.Cluster)
+				}
+				if len(changed) > 0 {
+					err = rowPrinter.Print(changed)
+				}
+			} else {
+				err = printClusterEvents(events)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		previous = shoots
+		time.Sleep(interval)
+	}
+}
+
+func clusterApply() error {
+	var docs []yaml.Node
+	var doc yaml.Node
+	err := helper.ReadFrom(viper.GetString("file"), &doc, func(data interface{}) {
+		docs = append(docs, *data.(*yaml.Node))
+		// the node needs to be renewed as otherwise the content of the previous document
+		// would be merged into the next one in the multi-document loop
+		doc = yaml.Node{}
+	})
 	if err != nil {
 		return err
 	}
-	return printer.Print(shoots.Payload)
+
+	// every document is decoded as create and as update request, which one is sent
+	// depends on whether the cluster already exists
+	ccrs := make([]models.V1ClusterCreateRequest, len(docs))
+	curs := make([]models.V1ClusterUpdateRequest, len(docs))
+	for i := range docs {
+		err = docs[i].Decode(&ccrs[i])
+		if err != nil {
+			return fmt.Errorf("decode error: %w", err)
+		}
+		err = docs[i].Decode(&curs[i])
+		if err != nil {
+			return fmt.Errorf("decode error: %w", err)
+		}
+	}
+
+	response := []*models.V1ClusterResponse{}
+	for i := range ccrs {
+		var current *models.V1ClusterResponse
+		if curs[i].ID != nil {
+			findRequest := cluster.NewFindClusterParams()
+			findRequest.SetID(*curs[i].ID)
+			resp, err := cloud.Cluster.FindCluster(findRequest, nil)
+			if err != nil {
+				return err
+			}
+			current = resp.Payload
+		} else {
+			current, err = clusterFindByName(ccrs[i].ProjectID, ccrs[i].Name)
+			if err != nil {
+				return err
+			}
+		}
+
+		if current == nil {
+			params := cluster.NewCreateClusterParams()
+			params.SetBody(&ccrs[i])
+			resp, err := cloud.Cluster.CreateCluster(params, nil)
+			if err != nil {
+				return err
+			}
+			response = append(response, resp.Payload)
+			continue
+		}
+
+		curs[i].ID = current.ID
+		params := cluster.NewUpdateClusterParams()
+		params.SetBody(&curs[i])
+		resp, err := cloud.Cluster.UpdateCluster(params, nil)
+		if err != nil {
+			return err
+		}
+		response = append(response, resp.Payload)
+	}
+	return printer.Print(response)
+}
+
+// clusterFindByName returns the cluster with the given name in the given project, nil if there is none.
+func clusterFindByName(project, name *string) (*models.V1ClusterResponse, error) {
+	if project == nil || name == nil {
+		return nil, fmt.Errorf("cluster without id requires name and project")
+	}
+	fcp := cluster.NewFindClustersParams()
+	fcp.SetBody(&models.V1ClusterFindRequest{
+		ProjectID: project,
+		Name:      name,
+	})
+	resp, err := cloud.Cluster.FindClusters(fcp, nil)
+	if err != nil {
+		return nil, err
+	}
+	switch len(resp.Payload) {
+	case 0:
+		return nil, nil
+	case 1:
+		return resp.Payload[0], nil
+	default:
+		return nil, fmt.Errorf("found %d clusters with name:%s in project:%s", len(resp.Payload), *name, *project)
+	}
 }
 
 func clusterKubeconfig(args []string) error {
@@ -579,16 +1235,121 @@ func clusterKubeconfig(args []string) error {
 		return fmt.Errorf("active user %s has no oidc authProvider, check config", authContext.User)
 	}
 
-	mergedKubeconfig, err := helper.EnrichKubeconfigTpl(kubeconfigContent, authContext)
+	var execCommand []string
+	if viper.GetBool("exec") {
+		execCommand, err = tokenExecCommand(kubeconfigFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	mergedKubeconfig, err := helper.EnrichKubeconfigTpl(kubeconfigContent, authContext, viper.GetString("context-name"), execCommand)
 	if err != nil {
 		return err
 	}
 
+	if viper.GetBool("merge") {
+		return mergeKubeconfig(mergedKubeconfig, viper.GetString("kubeconfig-path"), viper.GetBool("set-current"))
+	}
+
+	outputFile := viper.GetString("output-file")
+	if outputFile != "" {
+		err = writePrivateFile(outputFile, mergedKubeconfig)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("kubeconfig written to %s\n", outputFile)
+		return nil
+	}
+
 	// print kubeconfig
 	fmt.Println(string(mergedKubeconfig))
 	return nil
 }
 
+// tokenExecCommand returns the command kubectl calls to get credentials through cloudctl token
+// of the current context, a kubeconfig given explicitly is passed on with its absolute path.
+func tokenExecCommand(kubeconfig string) ([]string, error) {
+	command := []string{programName, "token"}
+	ctxs, err := getContexts()
+	if err != nil {
+		return nil, err
+	}
+	if ctxs.CurrentContext != "" {
+		command = append(command, "--context", ctxs.CurrentContext)
+	}
+	if kubeconfig != "" {
+		path, err := filepath.Abs(kubeconfig)
+		if err != nil {
+			return nil, err
+		}
+		command = append(command, "--kubeconfig", path)
+	}
+	return command, nil
+}
+
+// mergeKubeconfig merges the given cluster kubeconfig into the kubeconfig at the given path,
+// if path is empty the default kubeconfig location is used.
+func mergeKubeconfig(kubeconfig []byte, path string, setCurrentContext bool) error {
+	source := make(map[interface{}]interface{})
+	err := yaml.Unmarshal(kubeconfig, source)
+	if err != nil {
+		return err
+	}
+
+	var target map[interface{}]interface{}
+	if _, statErr := os.Stat(path); path != "" && os.IsNotExist(statErr) {
+		err = auth.CreateFromTemplate(&target)
+	} else {
+		target, path, _, err = auth.LoadKubeConfig(path)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = helper.MergeKubeconfig(target, source, setCurrentContext)
+	if err != nil {
+		return err
+	}
+
+	content, err := auth.EncodeKubeconfig(target)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return err
+	}
+	err = writePrivateFile(path, content.Bytes())
+	if err != nil {
+		return err
+	}
+
+	contextName, _ := source["current-context"].(string)
+	if setCurrentContext {
+		fmt.Printf("merged context %s into %s and switched to it\n", contextName, path)
+	} else {
+		fmt.Printf("merged context %s into %s\n", contextName, path)
+	}
+	return nil
+}
+
+// writePrivateFile writes the given content to a file which is only accessible by the current user
+func writePrivateFile(filename string, content []byte) error {
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("unable to write %s: %w", filename, err)
+	}
+	defer f.Close()
+	// permissions of an already existing file are not changed by open
+	err = f.Chmod(0600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(content)
+	return err
+}
+
 type sshkeypair struct {
 	privatekey []byte
 	publickey  []byte
@@ -616,14 +1377,6 @@ func sshKeyPair(clusterID string) (*sshkeypair, error) {
 }
 
 func reconcileCluster(args []string) error {
-	ci, err := clusterID("reconcile", args)
-	if err != nil {
-		return err
-	}
-
-	request := cluster.NewReconcileClusterParams()
-	request.SetID(ci)
-
 	if helper.ViperBool("retry") != nil && helper.ViperBool("maintain") != nil {
 		return fmt.Errorf("--retry and --maintain are mutually exclusive")
 	}
@@ -637,98 +1390,353 @@ func reconcileCluster(args []string) error {
 		o := "maintain"
 		operation = &o
 	}
-	request.Body = &models.V1ClusterReconcileRequest{Operation: operation}
+	if len(args) == 0 && clusterSelectorGiven() {
+		verb := "reconcile"
+		if operation != nil {
+			verb = *operation
+		}
+		return clusterBulkOperation(verb, func(shoot *models.V1ClusterResponse) (*models.V1ClusterResponse, error) {
+			return reconcileClusterOperation(*shoot.ID, operation)
+		})
+	}
 
-	shoot, err := cloud.Cluster.ReconcileCluster(request, nil)
+	ci, err := clusterID("reconcile", args)
 	if err != nil {
 		return err
 	}
-	return printer.Print(shoot.Payload)
-}
-
-func updateCluster(args []string) error {
-	ci, err := clusterID("update", args)
+	if clusterSelectorGiven() {
+		return fmt.Errorf("cluster reconcile requires either a clusterID or a selector, not both")
+	}
+	shoot, err := reconcileClusterOperation(ci, operation)
 	if err != nil {
 		return err
 	}
-	workergroupname := viper.GetString("workergroup")
-	minsize := viper.GetInt32("minsize")
-	maxsize := viper.GetInt32("maxsize")
-	version := viper.GetString("version")
-	firewallType := viper.GetString("firewalltype")
-	firewallImage := viper.GetString("firewallimage")
-	firewallController := viper.GetString("firewallcontroller")
-	firewallNetworks := viper.GetStringSlice("external-networks")
-	machineType := viper.GetString("machinetype")
-	machineImageAndVersion := viper.GetString("machineimage")
-	purpose := viper.GetString("purpose")
-	addLabels := viper.GetStringSlice("addlabels")
-	removeLabels := viper.GetStringSlice("removelabels")
-	egress := viper.GetStringSlice("egress")
-	maxsurge := viper.GetString("maxsurge")
-	maxunavailable := viper.GetString("maxunavailable")
+	return printClusterOrWait(shoot, nil, clusterWaitForSucceeded)
+}
 
-	findRequest := cluster.NewFindClusterParams()
-	findRequest.SetID(ci)
-	resp, err := cloud.Cluster.FindCluster(findRequest, nil)
+// reconcileClusterOperation triggers the given operation on the cluster, a regular reconcile if operation is nil
+func reconcileClusterOperation(ci string, operation *string) (*models.V1ClusterResponse, error) {
+	request := cluster.NewReconcileClusterParams()
+	request.SetID(ci)
+	request.Body = &models.V1ClusterReconcileRequest{Operation: operation}
+	shoot, err := cloud.Cluster.ReconcileCluster(request, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	current := resp.Payload
+	return shoot.Payload, nil
+}
 
-	healthtimeout := viper.GetDuration("healthtimeout")
-	draintimeout := viper.GetDuration("draintimeout")
+// clusterOperationConfirmation is the question asked before an operation is triggered on clusters
+func clusterOperationConfirmation(verb string, count int) string {
+	return fmt.Sprintf("This will %s %d cluster(s).", verb, count)
+}
 
-	request := cluster.NewUpdateClusterParams()
-	cur := &models.V1ClusterUpdateRequest{
-		ID: &ci,
-		Maintenance: &models.V1Maintenance{
-			AutoUpdate: &models.V1MaintenanceAutoUpdate{
-				KubernetesVersion: current.Maintenance.AutoUpdate.KubernetesVersion,
-				MachineImage:      current.Maintenance.AutoUpdate.MachineImage,
-			},
-		},
+// the flags of cluster update which can be applied to multiple clusters at once
+var clusterBulkUpdateFlags = []string{"version", "autoupdate-kubernetes", "autoupdate-machineimages", "maintenance-begin", "maintenance-end", "maintenance-timezone", "firewallcontroller", "purpose"}
+
+// updateClusters applies the update flags which are not specific to a single cluster to all selected clusters
+func updateClusters() error {
+	bulkFlags := sets.NewString(clusterBulkUpdateFlags...)
+	for _, f := range []string{"workergroup", "minsize", "maxsize", "firewalltype", "firewallimage", "machinetype", "machineimage", "addlabels", "removelabels",
+		"allowprivileged", "egress", "external-networks", "healthtimeout", "draintimeout", "maxsurge", "maxunavailable", "dry-run", "diff"} {
+		if viper.IsSet(f) && !bulkFlags.Has(f) {
+			return fmt.Errorf("--%s can only be used to update a single cluster, multiple clusters can only be updated with --%s", f, strings.Join(clusterBulkUpdateFlags, ", --"))
+		}
 	}
 
-	if minsize != 0 || maxsize != 0 || machineImageAndVersion != "" || machineType != "" || healthtimeout != 0 || draintimeout != 0 || maxsurge != "" || maxunavailable != "" {
-		workers := current.Workers
+	version := viper.GetString("version")
+	firewallController := viper.GetString("firewallcontroller")
+	purpose := viper.GetString("purpose")
+	maintenanceBegin := viper.GetString("maintenance-begin")
+	maintenanceEnd := viper.GetString("maintenance-end")
+	var timeWindow *models.V1MaintenanceTimeWindow
+	if maintenanceBegin != "" || maintenanceEnd != "" {
+		if maintenanceBegin == "" || maintenanceEnd == "" {
+			return fmt.Errorf("--maintenance-begin and --maintenance-end must be given together")
+		}
+		begin, end, err := helper.MaintenanceTimeWindow(maintenanceBegin, maintenanceEnd, viper.GetString("maintenance-timezone"))
+		if err != nil {
+			return err
+		}
+		timeWindow = &models.V1MaintenanceTimeWindow{Begin: &begin, End: &end}
+	}
+	if version == "" && firewallController == "" && purpose == "" && timeWindow == nil && !viper.IsSet("autoupdate-kubernetes") && !viper.IsSet("autoupdate-machineimages") {
+		return fmt.Errorf("no update given, multiple clusters can be updated with --%s", strings.Join(clusterBulkUpdateFlags, ", --"))
+	}
+	if version != "" {
+		sc, err := clusterConstraints()
+		if err != nil {
+			return err
+		}
+		err = checkConstraint("version", version, sc.KubernetesVersions)
+		if err != nil {
+			return err
+		}
+	}
 
-		var worker *models.V1Worker
-		if workergroupname != "" {
-			for _, w := range workers {
-				if w.Name != nil && *w.Name == workergroupname {
-					worker = w
-					break
-				}
+	return clusterBulkOperation("update", func(shoot *models.V1ClusterResponse) (*models.V1ClusterResponse, error) {
+		cur := &models.V1ClusterUpdateRequest{ID: shoot.ID}
+		if version != "" {
+			cur.Kubernetes = &models.V1Kubernetes{Version: &version}
+		}
+		if firewallController != "" {
+			cur.FirewallControllerVersion = &firewallController
+		}
+		if purpose != "" {
+			cur.Purpose = &purpose
+		}
+		if timeWindow != nil || viper.IsSet("autoupdate-kubernetes") || viper.IsSet("autoupdate-machineimages") {
+			autoUpdate := &models.V1MaintenanceAutoUpdate{}
+			if shoot.Maintenance != nil && shoot.Maintenance.AutoUpdate != nil {
+				autoUpdate.KubernetesVersion = shoot.Maintenance.AutoUpdate.KubernetesVersion
+				autoUpdate.MachineImage = shoot.Maintenance.AutoUpdate.MachineImage
 			}
-			if worker == nil {
-				return fmt.Errorf("no worker group found by name: %s", workergroupname)
+			if viper.IsSet("autoupdate-kubernetes") {
+				auto := viper.GetBool("autoupdate-kubernetes")
+				autoUpdate.KubernetesVersion = &auto
 			}
-		} else if len(workers) == 1 {
-			worker = workers[0]
-		} else {
-			return fmt.Errorf("there are multiple worker groups, please specify the worker group you want to update with --workergroup")
+			if viper.IsSet("autoupdate-machineimages") {
+				auto := viper.GetBool("autoupdate-machineimages")
+				autoUpdate.MachineImage = &auto
+			}
+			cur.Maintenance = &models.V1Maintenance{AutoUpdate: autoUpdate, TimeWindow: timeWindow}
 		}
 
-		if minsize != 0 {
-			worker.Minimum = &minsize
+		request := cluster.NewUpdateClusterParams()
+		request.SetBody(cur)
+		resp, err := cloud.Cluster.UpdateCluster(request, nil)
+		if err != nil {
+			return nil, err
 		}
-		if maxsize != 0 {
-			worker.Maximum = &maxsize
+		if len(output.NewClusterChanges(shoot, cur)) == 0 {
+			// nothing changed, so there is no operation to wait for
+			return shoot, nil
 		}
+		return resp.Payload, nil
+	})
+}
 
-		if machineImageAndVersion != "" {
-			machineImage := models.V1MachineImage{}
-			machineImageParts := strings.Split(machineImageAndVersion, "-")
-			if len(machineImageParts) == 2 {
-				machineImage = models.V1MachineImage{
-					Name:    &machineImageParts[0],
-					Version: &machineImageParts[1],
+// addClusterSelectorFlags adds the flags to apply a command to multiple clusters instead of the one given by uid
+func addClusterSelectorFlags(cmd *cobra.Command) {
+	cmd.Flags().String("project", "", "select the clusters of the given project instead of a single cluster.")
+	cmd.Flags().String("tenant", "", "select the clusters of the given tenant instead of a single cluster.")
+	cmd.Flags().String("partition", "", "select the clusters in the given partition instead of a single cluster.")
+	cmd.Flags().StringSliceP("selector", "l", []string{}, "select the clusters with the given labels in the form <key>=<value> instead of a single cluster.")
+	cmd.Flags().Int("parallel", 5, "maximum number of selected clusters which are processed at the same time.")
+	cmd.RegisterFlagCompletionFunc("project", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return projectListCompletion()
+	})
+	cmd.RegisterFlagCompletionFunc("partition", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return partitionListCompletion()
+	})
+}
+
+func clusterSelectorGiven() bool {
+	return viper.GetString("project") != "" || viper.GetString("tenant") != "" || viper.GetString("partition") != "" || len(viper.GetStringSlice("selector")) > 0
+}
+
+// selectClusters returns the clusters matching the selector flags
+func selectClusters() ([]*models.V1ClusterResponse, error) {
+	labels, err := helper.LabelsToMap(viper.GetStringSlice("selector"))
+	if err != nil {
+		return nil, err
+	}
+	shoots, err := findClusters(false)
+	if err != nil {
+		return nil, err
+	}
+	var selected []*models.V1ClusterResponse
+	for _, shoot := range shoots {
+		matches := true
+		for k, v := range labels {
+			if value, ok := shoot.Labels[k]; !ok || value != v {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			selected = append(selected, shoot)
+		}
+	}
+	return selected, nil
+}
+
+// clusterBulkOperation triggers the operation on all selected clusters after confirmation,
+// with --wait it waits for the operations to finish. The outcome is printed per cluster.
+func clusterBulkOperation(verb string, operation func(shoot *models.V1ClusterResponse) (*models.V1ClusterResponse, error)) error {
+	parallel := viper.GetInt("parallel")
+	if parallel < 1 {
+		return fmt.Errorf("parallel must be at least 1")
+	}
+	shoots, err := selectClusters()
+	if err != nil {
+		return err
+	}
+	if len(shoots) == 0 {
+		return fmt.Errorf("no clusters match the given selector")
+	}
+
+	if !viper.GetBool("yes-i-really-mean-it") {
+		err = printer.Print(shoots)
+		if err != nil {
+			return err
+		}
+		fmt.Println(clusterOperationConfirmation(verb, len(shoots)))
+		err = helper.Prompt("Are you sure? (y/n)", "y")
+		if err != nil {
+			return err
+		}
+	}
+
+	var (
+		wg      sync.WaitGroup
+		sem     = make(chan struct{}, parallel)
+		results = make(output.ClusterOperationResults, len(shoots))
+	)
+	for i, shoot := range shoots {
+		results[i] = output.ClusterOperationResult{ID: *shoot.ID, Name: *shoot.Name, Project: *shoot.ProjectID}
+
+		wg.Add(1)
+		go func(shoot *models.V1ClusterResponse, result *output.ClusterOperationResult) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			previousUpdate := ""
+			if shoot.Status != nil && shoot.Status.LastOperation != nil && shoot.Status.LastOperation.LastUpdateTime != nil {
+				previousUpdate = *shoot.Status.LastOperation.LastUpdateTime
+			}
+			current, err := operation(shoot)
+			// the operation returns the given cluster itself if it triggered nothing
+			if err == nil && viper.GetBool("wait") && current != shoot {
+				current, err = waitForCluster(*shoot.ID, clusterWaitForSucceeded, previousUpdate, viper.GetDuration("timeout"))
+			}
+			result.Operation = output.LastOperationString(current)
+			if err != nil {
+				result.Error = err.Error()
+			}
+		}(shoot, &results[i])
+	}
+	wg.Wait()
+
+	// FIXME this is a ugly hack to reset the printer and have a new header.
+	initPrinter()
+
+	err = printer.Print(results)
+	if err != nil {
+		return err
+	}
+
+	var failed []string
+	for _, r := range results {
+		if r.Error != "" {
+			failed = append(failed, fmt.Sprintf("%s (%s): %s", r.Name, r.ID, r.Error))
+		}
+	}
+	if len(failed) > 0 {
+		fmt.Fprintf(os.Stderr, "\n%s failed on %d of %d clusters:\n", verb, len(failed), len(results))
+		for _, f := range failed {
+			fmt.Fprintln(os.Stderr, f)
+		}
+		return fmt.Errorf("%s failed on %d of %d clusters", verb, len(failed), len(results))
+	}
+	return nil
+}
+
+func updateCluster(args []string) error {
+	if len(args) == 0 && clusterSelectorGiven() {
+		return updateClusters()
+	}
+	ci, err := clusterID("update", args)
+	if err != nil {
+		return err
+	}
+	if clusterSelectorGiven() {
+		return fmt.Errorf("cluster update requires either a clusterID or a selector, not both")
+	}
+	workergroupname := viper.GetString("workergroup")
+	minsize := viper.GetInt32("minsize")
+	maxsize := viper.GetInt32("maxsize")
+	version := viper.GetString("version")
+	firewallType := viper.GetString("firewalltype")
+	firewallImage := viper.GetString("firewallimage")
+	firewallController := viper.GetString("firewallcontroller")
+	firewallNetworks := viper.GetStringSlice("external-networks")
+	machineType := viper.GetString("machinetype")
+	machineImageAndVersion := viper.GetString("machineimage")
+	purpose := viper.GetString("purpose")
+	addLabels := viper.GetStringSlice("addlabels")
+	removeLabels := viper.GetStringSlice("removelabels")
+	egress := viper.GetStringSlice("egress")
+	maxsurge := viper.GetString("maxsurge")
+	maxunavailable := viper.GetString("maxunavailable")
+
+	findRequest := cluster.NewFindClusterParams()
+	findRequest.SetID(ci)
+	resp, err := cloud.Cluster.FindCluster(findRequest, nil)
+	if err != nil {
+		return err
+	}
+	current := resp.Payload
+
+	// the request is built from the current cluster which gets modified, keep its state for the diff
+	before := &models.V1ClusterResponse{}
+	content, err := current.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	err = before.UnmarshalBinary(content)
+	if err != nil {
+		return err
+	}
+
+	healthtimeout := viper.GetDuration("healthtimeout")
+	draintimeout := viper.GetDuration("draintimeout")
+
+	request := cluster.NewUpdateClusterParams()
+	cur := &models.V1ClusterUpdateRequest{
+		ID: &ci,
+		Maintenance: &models.V1Maintenance{
+			AutoUpdate: &models.V1MaintenanceAutoUpdate{
+				KubernetesVersion: current.Maintenance.AutoUpdate.KubernetesVersion,
+				MachineImage:      current.Maintenance.AutoUpdate.MachineImage,
+			},
+		},
+	}
+
+	if minsize != 0 || maxsize != 0 || machineImageAndVersion != "" || machineType != "" || healthtimeout != 0 || draintimeout != 0 || maxsurge != "" || maxunavailable != "" {
+		workers := current.Workers
+
+		var worker *models.V1Worker
+		if workergroupname != "" {
+			for _, w := range workers {
+				if w.Name != nil && *w.Name == workergroupname {
+					worker = w
+					break
 				}
-			} else {
-				log.Fatalf("given machineimage:%s is invalid must be in the form <name>-<version>", machineImageAndVersion)
 			}
-			worker.MachineImage = &machineImage
+			if worker == nil {
+				return fmt.Errorf("no worker group found by name: %s", workergroupname)
+			}
+		} else if len(workers) == 1 {
+			worker = workers[0]
+		} else {
+			return fmt.Errorf("there are multiple worker groups, please specify the worker group you want to update with --workergroup")
+		}
+
+		if minsize != 0 {
+			worker.Minimum = &minsize
+		}
+		if maxsize != 0 {
+			worker.Maximum = &maxsize
+		}
+
+		if machineImageAndVersion != "" {
+			machineImage, err := parseMachineImage(machineImageAndVersion)
+			if err != nil {
+				return err
+			}
+			worker.MachineImage = machineImage
 		}
 
 		if machineType != "" {
@@ -745,14 +1753,14 @@ func updateCluster(args []string) error {
 
 		if healthtimeout != 0 {
 			if !mcmMigrated {
-				log.Fatal("custom healthtimeout requires feature: machineControllerManagerOOT")
+				return fmt.Errorf("custom healthtimeout requires feature: machineControllerManagerOOT")
 			}
 			worker.HealthTimeout = int64(healthtimeout)
 		}
 
 		if draintimeout != 0 {
 			if !mcmMigrated {
-				log.Fatal("custom draintimeout requires feature: machineControllerManagerOOT")
+				return fmt.Errorf("custom draintimeout requires feature: machineControllerManagerOOT")
 			}
 			worker.DrainTimeout = int64(draintimeout)
 		}
@@ -776,6 +1784,21 @@ func updateCluster(args []string) error {
 		auto := viper.GetBool("autoupdate-machineimages")
 		cur.Maintenance.AutoUpdate.MachineImage = &auto
 	}
+	maintenanceBegin := viper.GetString("maintenance-begin")
+	maintenanceEnd := viper.GetString("maintenance-end")
+	if maintenanceBegin != "" || maintenanceEnd != "" {
+		if maintenanceBegin == "" || maintenanceEnd == "" {
+			return fmt.Errorf("--maintenance-begin and --maintenance-end must be given together")
+		}
+		begin, end, err := helper.MaintenanceTimeWindow(maintenanceBegin, maintenanceEnd, viper.GetString("maintenance-timezone"))
+		if err != nil {
+			return err
+		}
+		cur.Maintenance.TimeWindow = &models.V1MaintenanceTimeWindow{
+			Begin: &begin,
+			End:   &end,
+		}
+	}
 
 	updateCausesDowntime := false
 	if firewallImage != "" {
@@ -814,7 +1837,7 @@ func updateCluster(args []string) error {
 		for _, l := range addLabels {
 			parts := strings.SplitN(l, "=", 2)
 			if len(parts) != 2 {
-				log.Fatalf("provided labels must be in the form <key>=<value>, found: %s", l)
+				return fmt.Errorf("provided labels must be in the form <key>=<value>, found: %s", l)
 			}
 			labelMap[parts[0]] = parts[1]
 		}
@@ -835,10 +1858,60 @@ func updateCluster(args []string) error {
 	}
 	cur.Kubernetes = k8s
 
-	cur.EgressRules = makeEgressRules(egress)
+	cur.EgressRules, err = makeEgressRules(egress)
+	if err != nil {
+		return err
+	}
+
+	if version != "" || machineType != "" || machineImageAndVersion != "" || firewallType != "" || firewallImage != "" || len(firewallNetworks) > 0 {
+		sc, err := clusterConstraints()
+		if err != nil {
+			return err
+		}
+		checks := []error{
+			checkConstraint("version", version, sc.KubernetesVersions),
+			checkConstraint("machinetype", machineType, sc.MachineTypes),
+			checkConstraint("machineimage", machineImageAndVersion, machineImageNames(sc)),
+			checkConstraint("firewalltype", firewallType, sc.FirewallTypes),
+			checkConstraint("firewallimage", firewallImage, sc.FirewallImages),
+		}
+		for _, n := range firewallNetworks {
+			checks = append(checks, checkConstraint("external network", n, sc.Networks))
+		}
+		for _, err := range checks {
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if viper.GetBool("dry-run") {
+		if printer.Type() == "table" {
+			return output.YAMLPrinter{}.Print(cur)
+		}
+		return printer.Print(cur)
+	}
+
+	diff := viper.GetBool("diff")
+	if diff {
+		changes := output.NewClusterChanges(before, cur)
+		if len(changes) == 0 {
+			fmt.Println("no changes")
+			return nil
+		}
+		err = printer.Print(changes)
+		if err != nil {
+			return err
+		}
+		if changes.RollWorkers() {
+			fmt.Println(color.YellowString("Changes marked with * replace the worker nodes of the worker group."))
+		}
+	}
 
-	if updateCausesDowntime && !viper.GetBool("yes-i-really-mean-it") {
-		fmt.Println("This cluster update will cause downtime.")
+	if (updateCausesDowntime || diff) && !viper.GetBool("yes-i-really-mean-it") {
+		if updateCausesDowntime {
+			fmt.Println("This cluster update will cause downtime.")
+		}
 		err = helper.Prompt("Are you sure? (y/n)", "y")
 		if err != nil {
 			return err
@@ -850,7 +1923,89 @@ func updateCluster(args []string) error {
 	if err != nil {
 		return err
 	}
-	return printer.Print(shoot.Payload)
+	return printUpdatedClusterOrWait(before, cur, shoot.Payload)
+}
+
+func clusterEdit(args []string) error {
+	ci, err := clusterID("edit", args)
+	if err != nil {
+		return err
+	}
+
+	getFunc := func(id string) ([]byte, error) {
+		findRequest := cluster.NewFindClusterParams()
+		findRequest.SetID(id)
+		resp, err := cloud.Cluster.FindCluster(findRequest, nil)
+		if err != nil {
+			return nil, fmt.Errorf("cluster describe error:%w", err)
+		}
+		content, err := yaml.Marshal(clusterUpdateRequestFromResponse(resp.Payload))
+		if err != nil {
+			return nil, err
+		}
+		return content, nil
+	}
+	updateFunc := func(filename string) error {
+		curs, err := readClusterUpdateRequests(filename)
+		if err != nil {
+			return err
+		}
+		if curs[0].ID == nil || *curs[0].ID != ci {
+			return fmt.Errorf("cluster update error, id must not be changed:%s", ci)
+		}
+		ucp := cluster.NewUpdateClusterParams()
+		ucp.SetBody(&curs[0])
+		uresp, err := cloud.Cluster.UpdateCluster(ucp, nil)
+		if err != nil {
+			return err
+		}
+		return printer.Print(uresp.Payload)
+	}
+
+	return helper.Edit(ci, getFunc, updateFunc)
+}
+
+// clusterUpdateRequestFromResponse returns an update request containing all editable fields of the given cluster
+func clusterUpdateRequestFromResponse(c *models.V1ClusterResponse) *models.V1ClusterUpdateRequest {
+	cur := &models.V1ClusterUpdateRequest{
+		ID:                        c.ID,
+		Workers:                   c.Workers,
+		EgressRules:               c.EgressRules,
+		Labels:                    c.Labels,
+		Maintenance:               c.Maintenance,
+		Purpose:                   c.Purpose,
+		FirewallSize:              c.FirewallSize,
+		FirewallImage:             c.FirewallImage,
+		FirewallControllerVersion: c.FirewallControllerVersion,
+		AdditionalNetworks:        c.AdditionalNetworks,
+	}
+	if c.Kubernetes != nil {
+		// the expiration date is managed by the server
+		cur.Kubernetes = &models.V1Kubernetes{
+			Version:                   c.Kubernetes.Version,
+			AllowPrivilegedContainers: c.Kubernetes.AllowPrivilegedContainers,
+		}
+	}
+	return cur
+}
+
+func readClusterUpdateRequests(filename string) ([]models.V1ClusterUpdateRequest, error) {
+	var curs []models.V1ClusterUpdateRequest
+	var cur models.V1ClusterUpdateRequest
+	err := helper.ReadFrom(filename, &cur, func(data interface{}) {
+		doc := data.(*models.V1ClusterUpdateRequest)
+		curs = append(curs, *doc)
+		// the request needs to be renewed as otherwise the pointers in the request struct will
+		// always point to same last value in the multi-document loop
+		cur = models.V1ClusterUpdateRequest{}
+	})
+	if err != nil {
+		return curs, err
+	}
+	if len(curs) != 1 {
+		return curs, fmt.Errorf("cluster update error more or less than one cluster given:%d", len(curs))
+	}
+	return curs, nil
 }
 
 func clusterDelete(args []string) error {
@@ -887,71 +2042,151 @@ func clusterDelete(args []string) error {
 	if err != nil {
 		return err
 	}
-	return printer.Print(c.Payload)
+	return printClusterOrWait(c.Payload, nil, clusterWaitForDeleted)
 }
 
-func clusterDescribe(args []string) error {
-	ci, err := clusterID("describe", args)
+func clusterWait(args []string) error {
+	ci, err := clusterID("wait", args)
 	if err != nil {
 		return err
 	}
-	findRequest := cluster.NewFindClusterParams()
-	findRequest.SetID(ci)
-	shoot, err := cloud.Cluster.FindCluster(findRequest, nil)
+	condition := viper.GetString("for")
+	switch condition {
+	case clusterWaitForSucceeded, clusterWaitForDeleted:
+	default:
+		return fmt.Errorf("unsupported wait condition:%s, only %s or %s are supported", condition, clusterWaitForSucceeded, clusterWaitForDeleted)
+	}
+
+	shoot, err := waitForCluster(ci, condition, "", viper.GetDuration("timeout"))
 	if err != nil {
 		return err
 	}
-	return printer.Print(shoot.Payload)
+	if shoot == nil {
+		return nil
+	}
+	return printer.Print(shoot)
 }
 
-func clusterIssues(args []string) error {
-	if len(args) == 0 {
-		id := viper.GetString("id")
-		name := viper.GetString("name")
-		tenant := viper.GetString("tenant")
-		partition := viper.GetString("partition")
-		project := viper.GetString("project")
-		boolTrue := true
-		var cfr *models.V1ClusterFindRequest
-		if id != "" || name != "" || tenant != "" || partition != "" || project != "" {
-			cfr = &models.V1ClusterFindRequest{}
+func addClusterWaitFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("wait", false, "wait until the triggered operation of the cluster has finished.")
+	cmd.Flags().Duration("timeout", 30*time.Minute, "period after which waiting is aborted with an error, only used together with --wait.")
+}
 
-			if id != "" {
-				cfr.ID = &id
+// printClusterOrWait prints the given cluster, if --wait is given it waits for the triggered operation to finish first.
+// previous is the last operation of the cluster before the request, if it is unknown the last operation of the response is taken.
+func printClusterOrWait(shoot *models.V1ClusterResponse, previous *models.V1beta1LastOperation, condition string) error {
+	if !viper.GetBool("wait") {
+		return printer.Print(shoot)
+	}
+	if shoot == nil || shoot.ID == nil {
+		return fmt.Errorf("unable to wait for cluster, no cluster id returned")
+	}
+
+	// the last operation before the request belongs to a previous operation,
+	// so it must not be mistaken as result of the operation triggered right now
+	if previous == nil {
+		previous = lastOperation(shoot)
+	}
+	previousUpdate := ""
+	if previous != nil && previous.LastUpdateTime != nil {
+		previousUpdate = *previous.LastUpdateTime
+	}
+
+	result, err := waitForCluster(*shoot.ID, condition, previousUpdate, viper.GetDuration("timeout"))
+	if err != nil {
+		return err
+	}
+	if result == nil {
+		return nil
+	}
+	return printer.Print(result)
+}
+
+// waitForCluster polls the cluster until its last operation succeeded or until the cluster is gone,
+// depending on the given condition. A last operation which was updated at previousUpdate is not considered.
+// Progress is written to stderr to keep the output of the command parseable.
+func waitForCluster(id, condition, previousUpdate string, timeout time.Duration) (*models.V1ClusterResponse, error) {
+	deadline := time.Now().Add(timeout)
+	lastProgress := ""
+	for {
+		// find clusters is used instead of find cluster because it does not fail for deleted clusters
+		fcp := cluster.NewFindClustersParams()
+		fcp.SetBody(&models.V1ClusterFindRequest{ID: &id})
+		resp, err := cloud.Cluster.FindClusters(fcp, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(resp.Payload) == 0 {
+			if condition == clusterWaitForDeleted {
+				fmt.Fprintf(os.Stderr, "%s cluster:%s deleted\n", time.Now().Format(time.RFC3339), id)
+				return nil, nil
 			}
-			if name != "" {
-				cfr.Name = &name
+			return nil, fmt.Errorf("cluster:%s not found", id)
+		}
+		shoot := resp.Payload[0]
+
+		var op *models.V1beta1LastOperation
+		if shoot.Status != nil {
+			op = shoot.Status.LastOperation
+		}
+		if op != nil && op.State != nil && op.Type != nil {
+			progress := int32(0)
+			if op.Progress != nil {
+				progress = *op.Progress
 			}
-			if tenant != "" {
-				cfr.Tenant = &tenant
+			description := ""
+			if op.Description != nil {
+				description = *op.Description
 			}
-			if project != "" {
-				cfr.ProjectID = &project
+			p := fmt.Sprintf("%s %d%% [%s] %s", *op.State, progress, *op.Type, description)
+			if p != lastProgress {
+				fmt.Fprintf(os.Stderr, "%s cluster:%s %s\n", time.Now().Format(time.RFC3339), id, p)
+				lastProgress = p
 			}
-			if partition != "" {
-				cfr.PartitionID = &partition
+
+			current := op.LastUpdateTime == nil || *op.LastUpdateTime != previousUpdate
+			if condition == clusterWaitForDeleted && *op.Type != "Delete" {
+				// a failed operation from before the deletion is not relevant
+				current = false
 			}
-		}
-
-		if cfr != nil {
-			fcp := cluster.NewFindClustersParams().WithReturnMachines(&boolTrue)
-			fcp.SetBody(cfr)
-			response, err := cloud.Cluster.FindClusters(fcp, nil)
-			if err != nil {
-				return err
+			if current {
+				switch *op.State {
+				case "Failed", "Error":
+					return shoot, fmt.Errorf("operation %s of cluster:%s ended in state %s: %s", *op.Type, id, *op.State, description)
+				case "Succeeded":
+					if condition == clusterWaitForSucceeded {
+						return shoot, nil
+					}
+				}
 			}
-			return printer.Print(output.ShootIssuesResponses(response.Payload))
 		}
 
-		request := cluster.NewListClustersParams().WithReturnMachines(&boolTrue)
-		shoots, err := cloud.Cluster.ListClusters(request, nil)
-		if err != nil {
-			return err
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timeout after %s while waiting for cluster:%s to be %s", timeout, id, condition)
 		}
-		return printer.Print(output.ShootIssuesResponses(shoots.Payload))
+		time.Sleep(clusterWaitInterval)
+	}
+}
+
+// printUpdatedClusterOrWait prints the updated cluster like printClusterOrWait,
+// an update which does not change the cluster triggers no operation, so there is nothing to wait for.
+func printUpdatedClusterOrWait(before *models.V1ClusterResponse, cur *models.V1ClusterUpdateRequest, shoot *models.V1ClusterResponse) error {
+	if viper.GetBool("wait") && len(output.NewClusterChanges(before, cur)) == 0 {
+		fmt.Fprintf(os.Stderr, "cluster:%s is unchanged, no operation was triggered\n", output.StrValue(before.ID))
+		return printer.Print(shoot)
+	}
+	return printClusterOrWait(shoot, lastOperation(before), clusterWaitForSucceeded)
+}
+
+func lastOperation(shoot *models.V1ClusterResponse) *models.V1beta1LastOperation {
+	if shoot == nil || shoot.Status == nil {
+		return nil
 	}
+	return shoot.Status.LastOperation
+}
 
-	ci, err := clusterID("issues", args)
+func clusterDescribe(args []string) error {
+	ci, err := clusterID("describe", args)
 	if err != nil {
 		return err
 	}
@@ -961,7 +2196,113 @@ func clusterIssues(args []string) error {
 	if err != nil {
 		return err
 	}
-	return printer.Print(output.ShootIssuesResponse(shoot.Payload))
+	// the printer of wide, markdown and template output is a table printer as well, so the format is checked
+	if viper.GetString("output-format") != "table" {
+		return printer.Print(shoot.Payload)
+	}
+	return output.DescribeShoot(os.Stdout, shoot.Payload)
+}
+
+func clusterIssues(args []string) error {
+	monitoringOutput := viper.GetString("monitoring-output")
+	shoots, err := clusterIssuesShoots(args)
+	if err != nil {
+		if monitoringOutput == "nagios" {
+			// a check which failed itself is unknown for nagios
+			return exitCodeError(output.PrintShootIssuesNagiosError(os.Stdout, err))
+		}
+		return err
+	}
+
+	switch monitoringOutput {
+	case "":
+		if len(args) == 0 {
+			err = printer.Print(output.ShootIssuesResponses(shoots))
+		} else {
+			err = printer.Print(output.ShootIssuesResponse(shoots[0]))
+		}
+		if err != nil {
+			return err
+		}
+	case "nagios":
+		// a nagios plugin always reports its state by the exit code
+		return exitCodeError(output.PrintShootIssuesNagios(os.Stdout, shoots))
+	case "openmetrics":
+		output.PrintShootIssuesOpenMetrics(os.Stdout, shoots)
+	default:
+		return fmt.Errorf("monitoring-output must be one of nagios|openmetrics, got:%s", monitoringOutput)
+	}
+
+	if viper.GetBool("exit-code") {
+		severity := output.ShootIssuesSeverity(shoots)
+		if severity != output.IssueSeverityNone {
+			return exitCodeError(severity)
+		}
+	}
+	return nil
+}
+
+// clusterIssuesShoots returns the clusters to check for issues, the one given by args or the ones matching the filter flags
+func clusterIssuesShoots(args []string) ([]*models.V1ClusterResponse, error) {
+	var shoots []*models.V1ClusterResponse
+	if len(args) == 0 {
+		id := viper.GetString("id")
+		name := viper.GetString("name")
+		tenant := viper.GetString("tenant")
+		partition := viper.GetString("partition")
+		project := viper.GetString("project")
+		boolTrue := true
+		var cfr *models.V1ClusterFindRequest
+		if id != "" || name != "" || tenant != "" || partition != "" || project != "" {
+			cfr = &models.V1ClusterFindRequest{}
+
+			if id != "" {
+				cfr.ID = &id
+			}
+			if name != "" {
+				cfr.Name = &name
+			}
+			if tenant != "" {
+				cfr.Tenant = &tenant
+			}
+			if project != "" {
+				cfr.ProjectID = &project
+			}
+			if partition != "" {
+				cfr.PartitionID = &partition
+			}
+		}
+
+		if cfr != nil {
+			fcp := cluster.NewFindClustersParams().WithReturnMachines(&boolTrue)
+			fcp.SetBody(cfr)
+			response, err := cloud.Cluster.FindClusters(fcp, nil)
+			if err != nil {
+				return nil, err
+			}
+			shoots = response.Payload
+		} else {
+			request := cluster.NewListClustersParams().WithReturnMachines(&boolTrue)
+			response, err := cloud.Cluster.ListClusters(request, nil)
+			if err != nil {
+				return nil, err
+			}
+			shoots = response.Payload
+		}
+	} else {
+		ci, err := clusterID("issues", args)
+		if err != nil {
+			return nil, err
+		}
+		findRequest := cluster.NewFindClusterParams()
+		findRequest.SetID(ci)
+		shoot, err := cloud.Cluster.FindCluster(findRequest, nil)
+		if err != nil {
+			return nil, err
+		}
+		shoots = []*models.V1ClusterResponse{shoot.Payload}
+	}
+	return shoots, nil
 }
 
 func clusterMachines(args []string) error {
@@ -992,24 +2333,242 @@ func clusterMachines(args []string) error {
 	return printer.Print(ms)
 }
 
+func clusterWorkerGroupList(args []string) error {
+	ci, err := clusterID("workergroup list", args)
+	if err != nil {
+		return err
+	}
+	findRequest := cluster.NewFindClusterParams()
+	findRequest.SetID(ci)
+	shoot, err := cloud.Cluster.FindCluster(findRequest, nil)
+	if err != nil {
+		return err
+	}
+	return printer.Print(shoot.Payload.Workers)
+}
+
+func clusterWorkerGroupAdd(args []string) error {
+	ci, err := clusterID("workergroup add", args)
+	if err != nil {
+		return err
+	}
+	name := viper.GetString("name")
+	machineType := viper.GetString("machinetype")
+	machineImageAndVersion := viper.GetString("machineimage")
+	cri := viper.GetString("cri")
+	minsize := viper.GetInt32("minsize")
+	maxsize := viper.GetInt32("maxsize")
+	maxsurge := viper.GetString("maxsurge")
+	maxunavailable := viper.GetString("maxunavailable")
+
+	findRequest := cluster.NewFindClusterParams()
+	findRequest.SetID(ci)
+	resp, err := cloud.Cluster.FindCluster(findRequest, nil)
+	if err != nil {
+		return err
+	}
+	current := resp.Payload
+
+	worker := &models.V1Worker{
+		Name:           &name,
+		Minimum:        &minsize,
+		Maximum:        &maxsize,
+		MaxSurge:       &maxsurge,
+		MaxUnavailable: &maxunavailable,
+	}
+	for _, w := range current.Workers {
+		if w.Name != nil && *w.Name == name {
+			return fmt.Errorf("worker group %s already exists in cluster:%s", name, ci)
+		}
+	}
+	if len(current.Workers) > 0 {
+		// a new worker group looks like the first one unless specified otherwise
+		worker.MachineType = current.Workers[0].MachineType
+		worker.MachineImage = current.Workers[0].MachineImage
+		worker.CRI = current.Workers[0].CRI
+	}
+	if machineType != "" {
+		worker.MachineType = &machineType
+	}
+	if machineImageAndVersion != "" {
+		worker.MachineImage, err = parseMachineImage(machineImageAndVersion)
+		if err != nil {
+			return err
+		}
+	}
+	if cri != "" {
+		worker.CRI = &cri
+	}
+	if minsize > maxsize {
+		return fmt.Errorf("minsize %d of worker group %s must not be greater than maxsize %d", minsize, name, maxsize)
+	}
+	// values taken over from the first worker group are not checked, they might not be available anymore
+	sc, err := clusterConstraints()
+	if err != nil {
+		return err
+	}
+	given := &models.V1Worker{MachineType: &machineType, CRI: &cri}
+	if machineImageAndVersion != "" {
+		given.MachineImage = worker.MachineImage
+	}
+	err = validateWorkerConstraints(sc, given)
+	if err != nil {
+		return err
+	}
+
+	return updateClusterWorkers(current, append(current.Workers, worker))
+}
+
+func clusterWorkerGroupRemove(args []string) error {
+	ci, err := clusterID("workergroup remove", args)
+	if err != nil {
+		return err
+	}
+	name := viper.GetString("name")
+
+	findRequest := cluster.NewFindClusterParams()
+	findRequest.SetID(ci)
+	resp, err := cloud.Cluster.FindCluster(findRequest, nil)
+	if err != nil {
+		return err
+	}
+	current := resp.Payload
+
+	var workers []*models.V1Worker
+	for _, w := range current.Workers {
+		if w.Name != nil && *w.Name == name {
+			continue
+		}
+		workers = append(workers, w)
+	}
+	if len(workers) == len(current.Workers) {
+		return fmt.Errorf("no worker group found by name: %s", name)
+	}
+	if len(workers) == 0 {
+		return fmt.Errorf("worker group %s is the last worker group of the cluster and can not be removed", name)
+	}
+
+	if !viper.GetBool("yes-i-really-mean-it") {
+		fmt.Printf("Removing worker group %s deletes all of its worker nodes.\n", name)
+		err = helper.Prompt("Are you sure? (y/n)", "y")
+		if err != nil {
+			return err
+		}
+	}
+
+	return updateClusterWorkers(current, workers)
+}
+
+// updateClusterWorkers replaces the worker groups of the given cluster
+func updateClusterWorkers(current *models.V1ClusterResponse, workers []*models.V1Worker) error {
+	cur := &models.V1ClusterUpdateRequest{
+		ID:      current.ID,
+		Workers: workers,
+	}
+	if current.Maintenance != nil {
+		cur.Maintenance = &models.V1Maintenance{
+			AutoUpdate: current.Maintenance.AutoUpdate,
+		}
+	}
+
+	request := cluster.NewUpdateClusterParams()
+	request.SetBody(cur)
+	shoot, err := cloud.Cluster.UpdateCluster(request, nil)
+	if err != nil {
+		return err
+	}
+	return printUpdatedClusterOrWait(current, cur, shoot.Payload)
+}
+
 func clusterLogs(args []string) error {
 	ci, err := clusterID("logs", args)
 	if err != nil {
 		return err
 	}
+	if viper.GetBool("watch") {
+		return clusterLogsWatch(ci)
+	}
 	findRequest := cluster.NewFindClusterParams()
 	findRequest.SetID(ci)
 	shoot, err := cloud.Cluster.FindCluster(findRequest, nil)
 	if err != nil {
 		return err
 	}
+	return printClusterLogs(shoot.Payload)
+}
+
+// clusterLogsWatch prints the logs of the cluster and afterwards only condition transitions, operation progress and new errors
+func clusterLogsWatch(ci string) error {
+	interval, err := watchInterval()
+	if err != nil {
+		return err
+	}
+	var previous *models.V1ClusterResponse
+	for {
+		findRequest := cluster.NewFindClusterParams()
+		findRequest.SetID(ci)
+		shoot, err := cloud.Cluster.FindCluster(findRequest, nil)
+		if err != nil {
+			return err
+		}
+		if previous == nil && printer.Type() == "table" {
+			err = printClusterLogs(shoot.Payload)
+			if err == nil {
+				fmt.Printf("\nWatching for changes every %s:\n", interval)
+			}
+		} else {
+			err = printClusterEvents(output.ClusterLogEvents(previous, shoot.Payload))
+		}
+		if err != nil {
+			return err
+		}
+		previous = shoot.Payload
+		time.Sleep(interval)
+	}
+}
+
+// printClusterEvents prints events as log lines in table mode, otherwise as one document per event
+func printClusterEvents(events []output.ClusterEvent) error {
+	for _, e := range events {
+		var err error
+		switch printer.Type() {
+		case "table":
+			fmt.Println(e)
+		case "json":
+			err = json.NewEncoder(os.Stdout).Encode(e)
+		default:
+			fmt.Println("---")
+			err = printer.Print(e)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func watchInterval() (time.Duration, error) {
+	interval := viper.GetDuration("watch-interval")
+	if interval <= 0 {
+		return 0, fmt.Errorf("watch-interval must be positive, got:%s", interval)
+	}
+	return interval, nil
+}
+
+// addWatchFlags adds the flags to poll for changes
+func addWatchFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolP("watch", "w", false, "watch for changes and print only what changed.")
+	cmd.Flags().Duration("watch-interval", 5*time.Second, "interval in which changes are polled with --watch.")
+}
+
+func printClusterLogs(shoot *models.V1ClusterResponse) error {
 	var conditions []*models.V1beta1Condition
 	var lastOperation *models.V1beta1LastOperation
 	var lastErrors []*models.V1beta1LastError
-	if shoot.Payload != nil && shoot.Payload.Status != nil {
-		conditions = shoot.Payload.Status.Conditions
-		lastOperation = shoot.Payload.Status.LastOperation
-		lastErrors = shoot.Payload.Status.LastErrors
+	if shoot != nil && shoot.Status != nil {
+		conditions = shoot.Status.Conditions
+		lastOperation = shoot.Status.LastOperation
+		lastErrors = shoot.Status.LastErrors
 	}
 
 	if printer.Type() != "table" {
@@ -1026,7 +2585,7 @@ func clusterLogs(args []string) error {
 	}
 
 	fmt.Println("Conditions:")
-	err = printer.Print(conditions)
+	err := printer.Print(conditions)
 	if err != nil {
 		return err
 	}
@@ -1054,87 +2613,657 @@ func clusterInputs() error {
 		return err
 	}
 
-	return output.YAMLPrinter{}.Print(sc)
-}
+	// the constraints are the same for all partitions, so the partition is only checked for existence
+	if partition := viper.GetString("partition"); partition != "" {
+		found := false
+		for _, p := range sc.Payload.Partitions {
+			if p == partition {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("partition:%s is not available, must be one of %v", partition, sc.Payload.Partitions)
+		}
+		sc.Payload.Partitions = []string{partition}
+	}
 
-func clusterMachineSSH(args []string, console bool) error {
-	cid, err := clusterID("ssh", args)
+	kinds := viper.GetStringSlice("kind")
+	inputs, err := output.NewClusterInputs(sc.Payload, kinds...)
 	if err != nil {
 		return err
 	}
-	mid := viper.GetString("machineid")
+	if printer.Type() == "table" {
+		return printer.Print(inputs)
+	}
+
+	if len(kinds) > 0 {
+		filtered := &models.V1ShootConstraints{}
+		for _, kind := range kinds {
+			switch kind {
+			case output.ClusterInputVersions:
+				filtered.KubernetesVersions = sc.Payload.KubernetesVersions
+			case output.ClusterInputMachineTypes:
+				filtered.MachineTypes = sc.Payload.MachineTypes
+			case output.ClusterInputImages:
+				filtered.MachineImages = sc.Payload.MachineImages
+			case output.ClusterInputFirewallTypes:
+				filtered.FirewallTypes = sc.Payload.FirewallTypes
+			case output.ClusterInputFirewallImages:
+				filtered.FirewallImages = sc.Payload.FirewallImages
+			case output.ClusterInputNetworks:
+				filtered.Networks = sc.Payload.Networks
+			case output.ClusterInputPartitions:
+				filtered.Partitions = sc.Payload.Partitions
+			}
+		}
+		sc.Payload = filtered
+	}
+	return printer.Print(sc)
+}
 
+func clusterExport(args []string) error {
+	ci, err := clusterID("export", args)
+	if err != nil {
+		return err
+	}
 	findRequest := cluster.NewFindClusterParams()
-	findRequest.SetID(cid)
+	findRequest.SetID(ci)
 	shoot, err := cloud.Cluster.FindCluster(findRequest, nil)
 	if err != nil {
 		return err
 	}
+	return output.YAMLPrinter{}.Print(clusterCreateRequestFrom(shoot.Payload))
+}
 
-	keypair, err := sshKeyPair(cid)
+func clusterClone(args []string) error {
+	ci, err := clusterID("clone", args)
 	if err != nil {
 		return err
 	}
-	ms := shoot.Payload.Machines
-	ms = append(ms, shoot.Payload.Firewalls...)
-	for _, m := range ms {
-		if *m.ID == mid {
-			home, err := os.UserHomeDir()
-			if err != nil {
-				return fmt.Errorf("unable determine home directory:%w", err)
-			}
-			privateKeyFile := path.Join(home, "."+programName, "."+cid+".id_rsa")
-			err = ioutil.WriteFile(privateKeyFile, keypair.privatekey, 0600)
-			if err != nil {
-				return fmt.Errorf("unable to write private key:%s error:%w", privateKeyFile, err)
-			}
-			defer os.Remove(privateKeyFile)
-			if console {
-				fmt.Printf("access console via ssh\n")
-				bmcConsolePort := "5222"
-				err := ssh("-i", privateKeyFile, mid+"@"+consoleHost, "-p", bmcConsolePort)
-				return err
-			}
-			networks := m.Allocation.Networks
-			feature := m.Allocation.Image.Features[0]
-			switch feature {
-			case "firewall":
-				for _, nw := range networks {
-					if *nw.Underlay || *nw.Private {
-						continue
-					}
-					for _, ip := range nw.Ips {
-						if portOpen(ip, "22", time.Second) {
-							err := ssh("-i", privateKeyFile, "metal"+"@"+ip)
-							return err
-						}
-					}
-				}
-				return fmt.Errorf("no ip with a open ssh port found")
-			case "machine":
-				// FIXME metal user is not allowed to execute
-				// ip vrf exec <tenantvrf> ssh <machineip>
-				return fmt.Errorf("machine access via ssh not implemented")
-			default:
-				return fmt.Errorf("unknown machine type:%s", feature)
-			}
-		}
+	findRequest := cluster.NewFindClusterParams()
+	findRequest.SetID(ci)
+	shoot, err := cloud.Cluster.FindCluster(findRequest, nil)
+	if err != nil {
+		return err
 	}
 
-	return fmt.Errorf("machine:%s not found in cluster:%s", mid, cid)
-}
-
-func ssh(args ...string) error {
-	path, err := exec.LookPath("ssh")
+	ccr := clusterCreateRequestFrom(shoot.Payload)
+	name := viper.GetString("name")
+	ccr.Name = &name
+	if project := viper.GetString("project"); project != "" {
+		ccr.ProjectID = &project
+	}
+	if partition := viper.GetString("partition"); partition != "" {
+		ccr.PartitionID = &partition
+	}
+	// static egress ips are still used by the cloned cluster and can not be taken over
+	if len(ccr.EgressRules) > 0 {
+		fmt.Fprintf(os.Stderr, "egress rules of cluster %s are not cloned, static egress ips can only be used by one cluster\n", ci)
+		ccr.EgressRules = nil
+	}
+	// external networks belong to partition and project
+	if viper.IsSet("external-networks") {
+		ccr.AdditionalNetworks = viper.GetStringSlice("external-networks")
+	} else if len(ccr.AdditionalNetworks) > 0 && (output.StrValue(ccr.PartitionID) != output.StrValue(shoot.Payload.PartitionID) || output.StrValue(ccr.ProjectID) != output.StrValue(shoot.Payload.ProjectID)) {
+		fmt.Fprintf(os.Stderr, "external networks of cluster %s are not cloned into another partition or project, they can be given with --external-networks\n", ci)
+		ccr.AdditionalNetworks = nil
+	}
+
+	request := cluster.NewCreateClusterParams()
+	request.SetBody(ccr)
+	response, err := cloud.Cluster.CreateCluster(request, nil)
+	if err != nil {
+		return err
+	}
+	return printClusterOrWait(response.Payload, nil, clusterWaitForSucceeded)
+}
+
+func clusterUpgradePlan(args []string) error {
+	var shoots []*models.V1ClusterResponse
+	if len(args) > 0 {
+		ci, err := clusterID("upgrade-plan", args)
+		if err != nil {
+			return err
+		}
+		findRequest := cluster.NewFindClusterParams()
+		findRequest.SetID(ci)
+		shoot, err := cloud.Cluster.FindCluster(findRequest, nil)
+		if err != nil {
+			return err
+		}
+		shoots = append(shoots, shoot.Payload)
+	} else {
+		var err error
+		// the machines are required to find the worker groups running expiring images
+		shoots, err = findClusters(viper.GetBool("machine-images"))
+		if err != nil {
+			return err
+		}
+	}
+
+	constraints, err := cloud.Cluster.ListConstraints(cluster.NewListConstraintsParams(), nil)
+	if err != nil {
+		return err
+	}
+
+	plans := output.ClusterUpgradePlans{}
+	for _, shoot := range shoots {
+		plan, err := output.NewClusterUpgradePlan(shoot, constraints.Payload, viper.GetBool("machine-images"))
+		if err != nil {
+			return err
+		}
+		plans = append(plans, *plan)
+	}
+	err = printer.Print(plans)
+	if err != nil {
+		return err
+	}
+
+	if printer.Type() != "table" {
+		return nil
+	}
+	var commands []string
+	for _, p := range plans {
+		commands = append(commands, p.Commands...)
+	}
+	if len(commands) > 0 {
+		fmt.Println("\nRun the updates one after another, each update must have finished before the next one is started:")
+		for _, c := range commands {
+			fmt.Println(c)
+		}
+	}
+	return nil
+}
+
+// clusterCreateRequestFrom returns the create request for a cluster with the same configuration as the given cluster,
+// fields which are managed by the server like id, status and machines are left out.
+func clusterCreateRequestFrom(shoot *models.V1ClusterResponse) *models.V1ClusterCreateRequest {
+	ccr := &models.V1ClusterCreateRequest{
+		Name:                      shoot.Name,
+		Description:               shoot.Description,
+		ProjectID:                 shoot.ProjectID,
+		PartitionID:               shoot.PartitionID,
+		Purpose:                   shoot.Purpose,
+		Labels:                    shoot.Labels,
+		FirewallSize:              shoot.FirewallSize,
+		FirewallImage:             shoot.FirewallImage,
+		FirewallControllerVersion: shoot.FirewallControllerVersion,
+		AdditionalNetworks:        shoot.AdditionalNetworks,
+		EgressRules:               shoot.EgressRules,
+		Maintenance:               shoot.Maintenance,
+		Workers:                   shoot.Workers,
+	}
+	if shoot.Kubernetes != nil {
+		ccr.Kubernetes = &models.V1Kubernetes{
+			AllowPrivilegedContainers: shoot.Kubernetes.AllowPrivilegedContainers,
+			Version:                   shoot.Kubernetes.Version,
+		}
+	}
+	return ccr
+}
+
+func clusterMachineSSH(args []string, command []string, console bool) error {
+	cid, err := clusterID("ssh", args)
+	if err != nil {
+		return err
+	}
+	mid := viper.GetString("machineid")
+
+	findRequest := cluster.NewFindClusterParams()
+	findRequest.SetID(cid)
+	shoot, err := cloud.Cluster.FindCluster(findRequest, nil)
+	if err != nil {
+		return err
+	}
+
+	keypair, err := sshKeyPair(cid)
+	if err != nil {
+		return err
+	}
+	ms := shoot.Payload.Machines
+	ms = append(ms, shoot.Payload.Firewalls...)
+	for _, m := range ms {
+		if *m.ID != mid {
+			continue
+		}
+		if console {
+			knownHosts, err := clusterKnownHostsFile(cid)
+			if err != nil {
+				return err
+			}
+			config, err := helper.SSHClientConfig(mid, keypair.privatekey, knownHosts)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("access console via ssh\n")
+			bmcConsolePort := "5222"
+			client, err := helper.SSHDial(net.JoinHostPort(consoleHost, bmcConsolePort), config, nil)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+			return helper.SSHShell(client)
+		}
+
+		client, closeClient, err := clusterMachineSSHClient(shoot.Payload, m, keypair.privatekey)
+		if err != nil {
+			return err
+		}
+		defer closeClient()
+		if len(command) > 0 {
+			return helper.SSHRun(client, helper.ShellJoin(command), os.Stdin, os.Stdout, os.Stderr)
+		}
+		return helper.SSHShell(client)
+	}
+
+	return fmt.Errorf("machine:%s not found in cluster:%s", mid, cid)
+}
+
+func clusterExec(args []string, command []string) error {
+	cid, err := clusterID("exec", args)
+	if err != nil {
+		return err
+	}
+	parallel := viper.GetInt("parallel")
+	if parallel < 1 {
+		return fmt.Errorf("parallel must be at least 1")
+	}
+
+	findRequest := cluster.NewFindClusterParams()
+	findRequest.SetID(cid)
+	shoot, err := cloud.Cluster.FindCluster(findRequest, nil)
+	if err != nil {
+		return err
+	}
+
+	var ms []*models.ModelsV1MachineResponse
+	switch machines := viper.GetString("machines"); machines {
+	case "all":
+		ms = append(ms, shoot.Payload.Firewalls...)
+		ms = append(ms, shoot.Payload.Machines...)
+	case "firewalls":
+		ms = shoot.Payload.Firewalls
+	case "workers":
+		ms = shoot.Payload.Machines
+	default:
+		return fmt.Errorf("machines must be one of all|firewalls|workers, got:%s", machines)
+	}
+	if len(ms) == 0 {
+		return fmt.Errorf("cluster:%s has no machines to execute the command on", cid)
+	}
+
+	keypair, err := sshKeyPair(cid)
+	if err != nil {
+		return err
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		sem     = make(chan struct{}, parallel)
+		results = make(output.MachineExecResults, len(ms))
+		cmdline = helper.ShellJoin(command)
+	)
+	for i, m := range ms {
+		result := output.MachineExecResult{ID: *m.ID, ExitCode: -1}
+		if m.Allocation != nil {
+			result.Name = output.StrValue(m.Allocation.Name)
+			if m.Allocation.Image != nil && len(m.Allocation.Image.Features) > 0 {
+				result.Type = m.Allocation.Image.Features[0]
+			}
+		}
+		results[i] = result
+
+		wg.Add(1)
+		go func(m *models.ModelsV1MachineResponse, result *output.MachineExecResult) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			prefix := result.Name
+			if prefix == "" {
+				prefix = result.ID
+			}
+			stdout := helper.NewPrefixWriter(os.Stdout, prefix+": ", &mu)
+			stderr := helper.NewPrefixWriter(os.Stderr, prefix+": ", &mu)
+			defer func() {
+				_ = stdout.Flush()
+				_ = stderr.Flush()
+			}()
+
+			client, closeClient, err := clusterMachineSSHClient(shoot.Payload, m, keypair.privatekey)
+			if err != nil {
+				result.Error = err.Error()
+				return
+			}
+			defer closeClient()
+
+			err = helper.SSHRun(client, cmdline, nil, stdout, stderr)
+			var exitErr *gossh.ExitError
+			switch {
+			case err == nil:
+				result.ExitCode = 0
+			case errors.As(err, &exitErr):
+				result.ExitCode = exitErr.ExitStatus()
+			default:
+				result.Error = err.Error()
+			}
+		}(m, &results[i])
+	}
+	wg.Wait()
+
+	fmt.Println()
+	err = printer.Print(results)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.ExitCode != 0 {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("command failed on %d of %d machines", failed, len(results))
+	}
+	return nil
+}
+
+func clusterPortForward(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("cluster port-forward requires the clusterid and <localport>:<host>:<port> as arguments")
+	}
+	cid := args[0]
+	localPort, remoteAddress, err := parsePortForward(args[1])
+	if err != nil {
+		return err
+	}
+	mid := viper.GetString("machineid")
+
+	findRequest := cluster.NewFindClusterParams()
+	findRequest.SetID(cid)
+	shoot, err := cloud.Cluster.FindCluster(findRequest, nil)
+	if err != nil {
+		return err
+	}
+	var firewall *models.ModelsV1MachineResponse
+	for _, fw := range shoot.Payload.Firewalls {
+		if *fw.ID == mid {
+			firewall = fw
+			break
+		}
+	}
+	if firewall == nil {
+		return fmt.Errorf("firewall:%s not found in cluster:%s", mid, cid)
+	}
+
+	keypair, err := sshKeyPair(cid)
+	if err != nil {
+		return err
+	}
+	client, closeClient, err := clusterMachineSSHClient(shoot.Payload, firewall, keypair.privatekey)
+	if err != nil {
+		return err
+	}
+	defer closeClient()
+
+	listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", localPort))
+	if err != nil {
+		return fmt.Errorf("unable to listen on local port:%s %w", localPort, err)
+	}
+	defer listener.Close()
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+	go func() {
+		<-interrupt
+		listener.Close()
+	}()
+
+	fmt.Printf("forwarding %s to %s through firewall %s, press ctrl-c to stop\n", listener.Addr(), remoteAddress, mid)
+	return helper.SSHForward(client, listener, remoteAddress, os.Stderr)
+}
+
+func clusterSSHConfig(args []string) error {
+	cid, err := clusterID("ssh-config", args)
+	if err != nil {
+		return err
+	}
+
+	findRequest := cluster.NewFindClusterParams()
+	findRequest.SetID(cid)
+	shoot, err := cloud.Cluster.FindCluster(findRequest, nil)
+	if err != nil {
+		return err
+	}
+	keypair, err := sshKeyPair(cid)
+	if err != nil {
+		return err
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("unable determine home directory:%w", err)
+	}
+	keyFile := path.Join(home, "."+programName, "ssh", cid, "id_rsa")
+	err = os.MkdirAll(filepath.Dir(keyFile), 0700)
+	if err != nil {
+		return err
+	}
+	err = writePrivateFile(keyFile, keypair.privatekey)
+	if err != nil {
+		return err
+	}
+	knownHosts, err := clusterKnownHostsFile(cid)
+	if err != nil {
+		return err
+	}
+
+	var config strings.Builder
+	hostEntry := func(name, ip, proxyJump string) {
+		fmt.Fprintf(&config, "Host %s\n", name)
+		fmt.Fprintf(&config, "  HostName %s\n", ip)
+		fmt.Fprintf(&config, "  User metal\n")
+		if proxyJump != "" {
+			fmt.Fprintf(&config, "  ProxyJump %s\n", proxyJump)
+		}
+		fmt.Fprintf(&config, "  IdentityFile %q\n", keyFile)
+		fmt.Fprintf(&config, "  IdentitiesOnly yes\n")
+		fmt.Fprintf(&config, "  UserKnownHostsFile %q\n\n", knownHosts)
+	}
+
+	fmt.Fprintf(&config, "# cluster %s (%s) of project %s\n", *shoot.Payload.Name, cid, *shoot.Payload.ProjectID)
+	jumpHost := ""
+	// machines without allocation name have no host name to connect to
+	for _, fw := range shoot.Payload.Firewalls {
+		if fw.Allocation == nil || output.StrValue(fw.Allocation.Name) == "" {
+			continue
+		}
+		ips := machinePublicIPs(fw)
+		if len(ips) == 0 {
+			continue
+		}
+		hostEntry(*fw.Allocation.Name, ips[0], "")
+		if jumpHost == "" {
+			jumpHost = *fw.Allocation.Name
+		}
+	}
+	for _, m := range shoot.Payload.Machines {
+		if m.Allocation == nil || output.StrValue(m.Allocation.Name) == "" {
+			continue
+		}
+		if jumpHost == "" {
+			return fmt.Errorf("cluster:%s has no firewall with a public ip to jump to the machines", cid)
+		}
+		ip, err := machinePrivateIP(m)
+		if err != nil {
+			return err
+		}
+		hostEntry(*m.Allocation.Name, ip, jumpHost)
+	}
+
+	if !viper.GetBool("merge") {
+		fmt.Print(config.String())
+		return nil
+	}
+
+	sshConfig := viper.GetString("ssh-config-path")
+	if sshConfig == "" {
+		sshConfig = path.Join(home, ".ssh", "config")
+	}
+	content, err := ioutil.ReadFile(sshConfig)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	begin := fmt.Sprintf("# BEGIN %s cluster %s", programName, cid)
+	end := fmt.Sprintf("# END %s cluster %s", programName, cid)
+	// ssh takes the first value of an option for a host, so the entries are put at the beginning
+	// to not be overridden by entries like Host * further down. Host * at the end of the block keeps
+	// global options at the beginning of the existing config valid for all hosts.
+	merged := helper.PrependMarkedBlock(string(content), begin, end, config.String()+"Host *\n")
+	err = os.MkdirAll(filepath.Dir(sshConfig), 0700)
+	if err != nil {
+		return err
+	}
+	err = writePrivateFile(sshConfig, []byte(merged))
 	if err != nil {
-		return fmt.Errorf("unable to locate ssh in path")
+		return err
+	}
+	fmt.Printf("merged ssh config of cluster %s into %s\n", cid, sshConfig)
+	return nil
+}
+
+// parsePortForward parses a port forward in the form <localport>:<host>:<port>
+func parsePortForward(s string) (string, string, error) {
+	parts := strings.SplitN(s, ":", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("port forward must be in the form <localport>:<host>:<port>, got:%s", s)
+	}
+	localPort := parts[0]
+	host, port, err := net.SplitHostPort(parts[1])
+	if err != nil || host == "" {
+		return "", "", fmt.Errorf("port forward must be in the form <localport>:<host>:<port>, got:%s", s)
+	}
+	for _, p := range []string{localPort, port} {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 1 || n > 65535 {
+			return "", "", fmt.Errorf("invalid port:%s in port forward:%s", p, s)
+		}
 	}
-	fmt.Printf("%s %s\n", path, strings.Join(args, " "))
-	cmd := exec.Command(path, args...)
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stdout
-	return cmd.Run()
+	return localPort, net.JoinHostPort(host, port), nil
+}
+
+// clusterMachineSSHClient connects to the given firewall or worker of the cluster, workers are only reachable
+// from the private network, so the firewall is used as jump host. The returned function closes all connections.
+func clusterMachineSSHClient(shoot *models.V1ClusterResponse, m *models.ModelsV1MachineResponse, privateKey []byte) (*gossh.Client, func(), error) {
+	knownHosts, err := clusterKnownHostsFile(*shoot.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	config, err := helper.SSHClientConfig("metal", privateKey, knownHosts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if m.Allocation == nil || m.Allocation.Image == nil || len(m.Allocation.Image.Features) == 0 {
+		return nil, nil, fmt.Errorf("machine:%s is not allocated", *m.ID)
+	}
+	feature := m.Allocation.Image.Features[0]
+	switch feature {
+	case "firewall":
+		ip, err := firewallSSHAddress(m)
+		if err != nil {
+			return nil, nil, err
+		}
+		client, err := helper.SSHDial(net.JoinHostPort(ip, "22"), config, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		return client, func() { client.Close() }, nil
+	case "machine":
+		ip, err := machinePrivateIP(m)
+		if err != nil {
+			return nil, nil, err
+		}
+		jumpHostIP, err := clusterSSHJumpHost(shoot.Firewalls)
+		if err != nil {
+			return nil, nil, err
+		}
+		jumpHost, err := helper.SSHDial(net.JoinHostPort(jumpHostIP, "22"), config, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		client, err := helper.SSHDial(net.JoinHostPort(ip, "22"), config, jumpHost)
+		if err != nil {
+			jumpHost.Close()
+			return nil, nil, err
+		}
+		return client, func() {
+			client.Close()
+			jumpHost.Close()
+		}, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown machine type:%s", feature)
+	}
+}
+
+// clusterKnownHostsFile returns the file the host keys of the machines of the given cluster are pinned in
+func clusterKnownHostsFile(clusterID string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("unable determine home directory:%w", err)
+	}
+	return path.Join(home, "."+programName, "known_hosts", clusterID), nil
+}
+
+// firewallSSHAddress returns the first public ip of the given firewall with an open ssh port
+func firewallSSHAddress(fw *models.ModelsV1MachineResponse) (string, error) {
+	for _, ip := range machinePublicIPs(fw) {
+		if portOpen(ip, "22", time.Second) {
+			return ip, nil
+		}
+	}
+	return "", fmt.Errorf("no ip with a open ssh port found on firewall:%s", *fw.ID)
+}
+
+// machinePublicIPs returns the ips of the given machine which are neither in the private nor in the underlay network
+func machinePublicIPs(m *models.ModelsV1MachineResponse) []string {
+	var ips []string
+	for _, nw := range m.Allocation.Networks {
+		if *nw.Underlay || *nw.Private {
+			continue
+		}
+		ips = append(ips, nw.Ips...)
+	}
+	return ips
+}
+
+// clusterSSHJumpHost returns the ssh address of the first reachable firewall of a cluster
+func clusterSSHJumpHost(firewalls []*models.ModelsV1MachineResponse) (string, error) {
+	for _, fw := range firewalls {
+		if fw.Allocation == nil {
+			continue
+		}
+		ip, err := firewallSSHAddress(fw)
+		if err == nil {
+			return ip, nil
+		}
+	}
+	return "", fmt.Errorf("no firewall with a open ssh port found to jump to the worker")
+}
+
+// machinePrivateIP returns the ip of the given machine in the private network of the cluster
+func machinePrivateIP(m *models.ModelsV1MachineResponse) (string, error) {
+	for _, nw := range m.Allocation.Networks {
+		if nw.Private == nil || !*nw.Private || len(nw.Ips) == 0 {
+			continue
+		}
+		return nw.Ips[0], nil
+	}
+	return "", fmt.Errorf("machine:%s has no ip in the private network", *m.ID)
 }
 
 func portOpen(ip string, port string, timeout time.Duration) bool {
@@ -1160,24 +3289,444 @@ func clusterID(verb string, args []string) (string, error) {
 	return "", fmt.Errorf("cluster %s requires exactly one clusterID as argument", verb)
 }
 
-func makeEgressRules(egressFlagValue []string) []*models.V1EgressRule {
-	if len(egressFlagValue) == 0 {
+// parseMachineImage parses a machine image given in the form <name>-<version>
+func parseMachineImage(machineImageAndVersion string) (*models.V1MachineImage, error) {
+	// the name of the image may contain dashes, the version does not
+	i := strings.LastIndex(machineImageAndVersion, "-")
+	if i <= 0 || i == len(machineImageAndVersion)-1 {
+		return nil, fmt.Errorf("given machineimage:%s is invalid must be in the form <name>-<version>", machineImageAndVersion)
+	}
+	name := machineImageAndVersion[:i]
+	version := machineImageAndVersion[i+1:]
+	return &models.V1MachineImage{
+		Name:    &name,
+		Version: &version,
+	}, nil
+}
+
+func clusterConstraints() (*models.V1ShootConstraints, error) {
+	sc, err := cloud.Cluster.ListConstraints(cluster.NewListConstraintsParams(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return sc.Payload, nil
+}
+
+// validateClusterCreateRequest checks the inputs of a cluster create request against the constraints of the cloud,
+// inputs which are not given are left to the defaults of the server.
+func validateClusterCreateRequest(sc *models.V1ShootConstraints, scr *models.V1ClusterCreateRequest) error {
+	checks := []error{
+		checkConstraint("partition", output.StrValue(scr.PartitionID), sc.Partitions),
+		checkConstraint("firewalltype", output.StrValue(scr.FirewallSize), sc.FirewallTypes),
+		checkConstraint("firewallimage", output.StrValue(scr.FirewallImage), sc.FirewallImages),
+	}
+	if scr.Kubernetes != nil {
+		checks = append(checks, checkConstraint("version", output.StrValue(scr.Kubernetes.Version), sc.KubernetesVersions))
+	}
+	for _, n := range scr.AdditionalNetworks {
+		checks = append(checks, checkConstraint("external network", n, sc.Networks))
+	}
+	for _, w := range scr.Workers {
+		checks = append(checks, validateWorkerConstraints(sc, w))
+	}
+	for _, err := range checks {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateWorkerConstraints checks machine type, machine image and container runtime of a worker group
+func validateWorkerConstraints(sc *models.V1ShootConstraints, w *models.V1Worker) error {
+	err := checkConstraint("machinetype", output.StrValue(w.MachineType), sc.MachineTypes)
+	if err != nil {
+		return err
+	}
+	if w.MachineImage != nil && w.MachineImage.Name != nil {
+		image := *w.MachineImage.Name + "-" + output.StrValue(w.MachineImage.Version)
+		err = checkConstraint("machineimage", image, machineImageNames(sc))
+		if err != nil {
+			return err
+		}
+	}
+	return checkConstraint("cri", output.StrValue(w.CRI), []string{"docker", "containerd"})
+}
+
+// checkConstraint returns an error if the given value of the input is not one of the valid values, empty values are not checked
+func checkConstraint(input, value string, valid []string) error {
+	if value == "" {
 		return nil
 	}
+	for _, v := range valid {
+		if v == value {
+			return nil
+		}
+	}
+	if closest := helper.ClosestMatch(value, valid); closest != "" {
+		return fmt.Errorf("%s:%s is not available, did you mean %s?", input, value, closest)
+	}
+	return fmt.Errorf("%s:%s is not available, must be one of %s", input, value, strings.Join(valid, ", "))
+}
+
+// machineImageNames returns the available machine images in the form <name>-<version>
+func machineImageNames(sc *models.V1ShootConstraints) []string {
+	var images []string
+	for _, image := range sc.MachineImages {
+		if image.Name == nil || image.Version == nil {
+			continue
+		}
+		images = append(images, *image.Name+"-"+*image.Version)
+	}
+	return images
+}
+
+func clusterEstimate() error {
+	machineType := viper.GetString("machinetype")
+	minsize := viper.GetInt32("minsize")
+	maxsize := viper.GetInt32("maxsize")
+	workers, err := clusterWorkers(models.V1Worker{
+		MachineType: &machineType,
+		Minimum:     &minsize,
+		Maximum:     &maxsize,
+	})
+	if err != nil {
+		return err
+	}
+	return printClusterEstimate(workers, viper.GetString("firewalltype"))
+}
+
+// printClusterEstimate prints the monthly cost range of a cluster with the given worker groups and firewall
+func printClusterEstimate(workers []*models.V1Worker, firewallType string) error {
+	if viper.GetFloat64("costs-cpu-hour") <= 0 && viper.GetFloat64("costs-memory-gi-hour") <= 0 && viper.GetFloat64("costs-storage-gi-hour") <= 0 {
+		return fmt.Errorf("no prices configured, costs-cpu-hour, costs-memory-gi-hour and costs-storage-gi-hour must be set in the configuration for an estimate")
+	}
+	if firewallType == "" {
+		return fmt.Errorf("firewalltype is required for an estimate")
+	}
+	sc, err := clusterConstraints()
+	if err != nil {
+		return err
+	}
+
+	estimate := &output.ClusterCostEstimate{}
+	for _, w := range workers {
+		machineType := output.StrValue(w.MachineType)
+		name := output.StrValue(w.Name)
+		if name == "" {
+			name = "worker"
+		}
+		if machineType == "" {
+			return fmt.Errorf("machinetype of worker group %s is required for an estimate", name)
+		}
+		resources, err := machineTypeResources(sc, machineType)
+		if err != nil {
+			return err
+		}
+		estimate.Add(name, machineType, resources, *w.Minimum, *w.Maximum)
+	}
+	resources, err := machineTypeResources(sc, firewallType)
+	if err != nil {
+		return err
+	}
+	estimate.Add("firewall", firewallType, resources, 1, 1)
+
+	return printer.Print(estimate)
+}
+
+// machineTypeResources returns the resources of the machine type from the configuration,
+// the constraints of the cloud api only contain the names of the machine types.
+func machineTypeResources(sc *models.V1ShootConstraints, machineType string) (output.MachineResources, error) {
+	err := checkConstraint("machinetype", machineType, append(append([]string{}, sc.MachineTypes...), sc.FirewallTypes...))
+	if err != nil {
+		return output.MachineResources{}, err
+	}
+	resources := map[string]output.MachineResources{}
+	err = viper.UnmarshalKey("machine-types", &resources)
+	if err != nil {
+		return output.MachineResources{}, fmt.Errorf("machine-types of the configuration are invalid:%w", err)
+	}
+	r, ok := resources[strings.ToLower(machineType)]
+	if !ok {
+		return output.MachineResources{}, fmt.Errorf("resources of machine type %s are unknown, add them to machine-types of the configuration", machineType)
+	}
+	return r, nil
+}
+
+// latestKubernetesVersion returns the latest kubernetes version of the constraints
+func latestKubernetesVersion(sc *models.V1ShootConstraints) (string, error) {
+	availableVersions := sc.KubernetesVersions
+	if len(availableVersions) == 0 {
+		return "", fmt.Errorf("no kubernetes versions available to deploy")
+	}
+
+	sortedVersions := make([]*semver.Version, len(availableVersions))
+	for i, r := range availableVersions {
+		v, err := semver.NewVersion(r)
+		if err != nil {
+			return "", fmt.Errorf("error parsing version: %w", err)
+		}
+
+		sortedVersions[i] = v
+	}
+
+	sort.Sort(semver.Collection(sortedVersions))
+
+	return sortedVersions[len(sortedVersions)-1].String(), nil
+}
+
+func clusterTemplateList() error {
+	templates, err := clusterTemplates()
+	if err != nil {
+		return err
+	}
+	return printer.Print(templates)
+}
+
+func clusterTemplateShow(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("cluster template show requires name as argument")
+	}
+	template, err := clusterTemplate(args[0])
+	if err != nil {
+		return err
+	}
+	return output.YAMLPrinter{}.Print(template)
+}
+
+// clusterTemplates returns the cluster templates of the configuration sorted by name,
+// templates of the current context take precedence over global templates with the same name.
+func clusterTemplates() (output.ClusterTemplates, error) {
+	ctxs, err := getContexts()
+	if err != nil {
+		return nil, err
+	}
+	byName := map[string]output.ClusterTemplate{}
+	for name := range ctxs.ClusterTemplates {
+		request := ctxs.ClusterTemplates[name]
+		byName[name] = output.ClusterTemplate{Name: name, Source: "global", Request: &request}
+	}
+	if ctx, ok := ctxs.Contexts[ctxs.CurrentContext]; ok {
+		for name := range ctx.ClusterTemplates {
+			request := ctx.ClusterTemplates[name]
+			byName[name] = output.ClusterTemplate{Name: name, Source: ctxs.CurrentContext, Request: &request}
+		}
+	}
+	templates := output.ClusterTemplates{}
+	for _, t := range byName {
+		templates = append(templates, t)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates, nil
+}
+
+// clusterTemplate returns the create request of the cluster template with the given name, nil if name is empty
+func clusterTemplate(name string) (*models.V1ClusterCreateRequest, error) {
+	if name == "" {
+		return nil, nil
+	}
+	templates, err := clusterTemplates()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, t := range templates {
+		if t.Name == name {
+			return t.Request, nil
+		}
+		names = append(names, t.Name)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("cluster template %s not found, there are no cluster-templates in the configuration", name)
+	}
+	if closest := helper.ClosestMatch(name, names); closest != "" {
+		return nil, fmt.Errorf("cluster template %s not found, did you mean %s?", name, closest)
+	}
+	return nil, fmt.Errorf("cluster template %s not found, must be one of %s", name, strings.Join(names, ", "))
+}
+
+// applyClusterTemplate takes the values of the template into the create request, except those which are given by flags
+func applyClusterTemplate(template, scr *models.V1ClusterCreateRequest) {
+	templateString := func(flag string, value *string, field **string) {
+		if value != nil && !viper.IsSet(flag) {
+			*field = value
+		}
+	}
+	templateString("name", template.Name, &scr.Name)
+	templateString("description", template.Description, &scr.Description)
+	templateString("project", template.ProjectID, &scr.ProjectID)
+	templateString("partition", template.PartitionID, &scr.PartitionID)
+	templateString("purpose", template.Purpose, &scr.Purpose)
+	templateString("firewalltype", template.FirewallSize, &scr.FirewallSize)
+	templateString("firewallimage", template.FirewallImage, &scr.FirewallImage)
+	templateString("firewallcontroller", template.FirewallControllerVersion, &scr.FirewallControllerVersion)
+	if template.Tenant != nil {
+		scr.Tenant = template.Tenant
+	}
+	if len(template.Labels) > 0 && !viper.IsSet("labels") {
+		scr.Labels = template.Labels
+	}
+	if len(template.AdditionalNetworks) > 0 && !viper.IsSet("external-networks") {
+		scr.AdditionalNetworks = template.AdditionalNetworks
+	}
+	if len(template.EgressRules) > 0 && !viper.IsSet("egress") {
+		scr.EgressRules = template.EgressRules
+	}
+	if k := template.Kubernetes; k != nil {
+		templateString("version", k.Version, &scr.Kubernetes.Version)
+		if k.AllowPrivilegedContainers != nil && !viper.IsSet("allowprivileged") {
+			scr.Kubernetes.AllowPrivilegedContainers = k.AllowPrivilegedContainers
+		}
+	}
+	if template.Maintenance != nil && !viper.IsSet("maintenance-begin") && !viper.IsSet("maintenance-end") && !viper.IsSet("maintenance-timezone") {
+		scr.Maintenance = template.Maintenance
+	}
+}
+
+// clusterTemplateWorkers returns the worker groups of the template with the worker values given by flags applied.
+// worker groups given by --worker replace those of the template, they take their defaults from the first worker group of the template.
+func clusterTemplateWorkers(template *models.V1ClusterCreateRequest, defaultWorker models.V1Worker) ([]*models.V1Worker, error) {
+	if template == nil || len(template.Workers) == 0 {
+		return clusterWorkers(defaultWorker)
+	}
+	if len(helper.ViperStringArray("worker")) > 0 {
+		return clusterWorkers(*templateWorker(*template.Workers[0], defaultWorker))
+	}
+	var workers []*models.V1Worker
+	for _, w := range template.Workers {
+		workers = append(workers, templateWorker(*w, defaultWorker))
+	}
+	return workers, nil
+}
+
+// templateWorker returns the worker group of a template with the values of the given worker group which are given by flags
+// or missing in the template
+func templateWorker(w models.V1Worker, flags models.V1Worker) *models.V1Worker {
+	use := func(flag string, missing bool) bool {
+		return missing || viper.IsSet(flag)
+	}
+	if use("machinetype", w.MachineType == nil) {
+		w.MachineType = flags.MachineType
+	}
+	if use("machineimage", w.MachineImage == nil) {
+		w.MachineImage = flags.MachineImage
+	}
+	if use("cri", w.CRI == nil) {
+		w.CRI = flags.CRI
+	}
+	if use("minsize", w.Minimum == nil) {
+		w.Minimum = flags.Minimum
+	}
+	if use("maxsize", w.Maximum == nil) {
+		w.Maximum = flags.Maximum
+	}
+	if use("maxsurge", w.MaxSurge == nil) {
+		w.MaxSurge = flags.MaxSurge
+	}
+	if use("maxunavailable", w.MaxUnavailable == nil) {
+		w.MaxUnavailable = flags.MaxUnavailable
+	}
+	if use("healthtimeout", w.HealthTimeout == 0) {
+		w.HealthTimeout = flags.HealthTimeout
+	}
+	if use("draintimeout", w.DrainTimeout == 0) {
+		w.DrainTimeout = flags.DrainTimeout
+	}
+	return &w
+}
+
+// clusterWorkers returns the worker groups given by --worker, or only the given default worker group if there are none
+func clusterWorkers(defaultWorker models.V1Worker) ([]*models.V1Worker, error) {
+	workerSpecs := helper.ViperStringArray("worker")
+	if len(workerSpecs) == 0 {
+		return []*models.V1Worker{&defaultWorker}, nil
+	}
+	var workers []*models.V1Worker
+	names := map[string]bool{}
+	for _, spec := range workerSpecs {
+		w, err := workerFromSpec(spec, defaultWorker)
+		if err != nil {
+			return nil, err
+		}
+		if names[*w.Name] {
+			return nil, fmt.Errorf("worker group %s is given multiple times", *w.Name)
+		}
+		names[*w.Name] = true
+		workers = append(workers, w)
+	}
+	return workers, nil
+}
+
+// workerFromSpec parses a worker group given in the form name=<name>,machinetype=<type>,min=<minsize>,max=<maxsize>,...
+// values which are not part of the spec are taken from the given defaults.
+func workerFromSpec(spec string, defaults models.V1Worker) (*models.V1Worker, error) {
+	w := defaults
+	w.Name = nil
+	for _, kv := range strings.Split(spec, ",") {
+		parts := strings.SplitN(strings.TrimSpace(kv), "=", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			return nil, fmt.Errorf("worker spec %q contains %q which is not in the form <key>=<value>", spec, kv)
+		}
+		key, value := parts[0], parts[1]
+		switch key {
+		case "name":
+			w.Name = &value
+		case "machinetype":
+			w.MachineType = &value
+		case "image":
+			machineImage, err := parseMachineImage(value)
+			if err != nil {
+				return nil, err
+			}
+			w.MachineImage = machineImage
+		case "cri":
+			w.CRI = &value
+		case "maxsurge":
+			w.MaxSurge = &value
+		case "maxunavailable":
+			w.MaxUnavailable = &value
+		case "min", "max":
+			size, err := strconv.ParseInt(value, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("worker spec %q contains invalid %s:%s", spec, key, value)
+			}
+			s := int32(size)
+			if key == "min" {
+				w.Minimum = &s
+			} else {
+				w.Maximum = &s
+			}
+		default:
+			return nil, fmt.Errorf("worker spec %q contains unknown key:%s, supported keys are name|machinetype|min|max|image|cri|maxsurge|maxunavailable", spec, key)
+		}
+	}
+	if w.Name == nil {
+		return nil, fmt.Errorf("worker spec %q requires a name", spec)
+	}
+	if w.Minimum != nil && w.Maximum != nil && *w.Minimum > *w.Maximum {
+		return nil, fmt.Errorf("worker group %s has a minimum of %d which is greater than its maximum of %d", *w.Name, *w.Minimum, *w.Maximum)
+	}
+	return &w, nil
+}
+
+func makeEgressRules(egressFlagValue []string) ([]*models.V1EgressRule, error) {
+	if len(egressFlagValue) == 0 {
+		return nil, nil
+	}
 
 	if len(egressFlagValue) == 1 && egressFlagValue[0] == "none" {
-		return []*models.V1EgressRule{}
+		return []*models.V1EgressRule{}, nil
 	}
 
 	m := map[string]models.V1EgressRule{}
 	for _, e := range egressFlagValue {
 		parts := strings.Split(e, ":")
 		if len(parts) != 2 {
-			log.Fatalf("egress config needs format <network>:<ip> but got %q", e)
+			return nil, fmt.Errorf("egress config needs format <network>:<ip> but got %q", e)
 		}
 		n, ip := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
 		if net.ParseIP(ip) == nil {
-			log.Fatalf("egress config contains an invalid IP %s for network %s", ip, n)
+			return nil, fmt.Errorf("egress config contains an invalid IP %s for network %s", ip, n)
 		}
 
 		if _, ok := m[n]; !ok {
@@ -1196,5 +3745,5 @@ func makeEgressRules(egressFlagValue []string) []*models.V1EgressRule {
 		r := e
 		egressRules = append(egressRules, &r)
 	}
-	return egressRules
+	return egressRules, nil
 }
@@ -0,0 +1,548 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fi-ts/cloud-go/api/client/ip"
+	"github.com/fi-ts/cloud-go/api/client/project"
+	"github.com/fi-ts/cloud-go/api/models"
+	"github.com/fi-ts/cloudctl/cmd/helper"
+	"github.com/fi-ts/cloudctl/cmd/output"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// clusterWizard asks for the values of a new cluster step by step, every answer is taken as value of the flag of cluster create
+type clusterWizard struct {
+	in  *bufio.Reader
+	out io.Writer
+	// args are the flags and values of the answers for the equivalent command line
+	args []string
+	// asked are the names of the flags which were answered
+	asked map[string]bool
+	// unchanged are the values the request has without an answer, an answer with the same value is not taken as flag.
+	// otherwise the flag would replace the values of the template, e.g. of all its worker groups.
+	unchanged map[string]string
+}
+
+// clusterCreateInteractive asks for the values of the cluster, prints the resulting create request
+// and the equivalent command line and creates the cluster after confirmation
+func clusterCreateInteractive(flags *pflag.FlagSet) error {
+	defaults, sc, err := clusterCreateRequestFromFlags()
+	if err != nil {
+		return err
+	}
+	projects, err := cloud.Project.ListProjects(project.NewListProjectsParams(), nil)
+	if err != nil {
+		return err
+	}
+
+	w := &clusterWizard{
+		in:        bufio.NewReader(os.Stdin),
+		out:       os.Stdout,
+		asked:     map[string]bool{},
+		unchanged: map[string]string{},
+	}
+	err = w.run(defaults, sc, projects.Payload.Projects)
+	if err != nil {
+		return err
+	}
+
+	scr, sc, err := clusterCreateRequestFromFlags()
+	if err != nil {
+		return err
+	}
+	err = checkClusterCreateRequest(sc, scr)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w.out, "\nCluster create request:")
+	err = output.YAMLPrinter{}.Print(scr)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w.out, "\nThe same cluster is created with:\n%s\n\n", w.commandLine(flags))
+
+	if !viper.GetBool("yes-i-really-mean-it") {
+		create, err := w.confirm("Create the cluster?", false)
+		if err != nil {
+			return err
+		}
+		if !create {
+			return fmt.Errorf("aborted, the cluster was not created")
+		}
+	}
+	return createCluster(scr)
+}
+
+func (w *clusterWizard) run(defaults *models.V1ClusterCreateRequest, sc *models.V1ShootConstraints, projects []*models.V1ProjectResponse) error {
+	var projectIDs []string
+	projectLabels := map[string]string{}
+	for _, p := range projects {
+		projectIDs = append(projectIDs, p.Meta.ID)
+		projectLabels[p.Meta.ID] = fmt.Sprintf("%s (tenant %s)", p.Name, p.TenantID)
+	}
+	sort.Strings(projectIDs)
+	defaultProject := output.StrValue(defaults.ProjectID)
+	if defaultProject == "" && len(projectIDs) == 1 {
+		defaultProject = projectIDs[0]
+	}
+	projectID, err := w.choose("project", "Project", projectIDs, projectLabels, defaultProject, false)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.ask("name", "Name of the cluster, max 10 characters", output.StrValue(defaults.Name), func(name string) (string, error) {
+		if name == "" {
+			return "", fmt.Errorf("name is required")
+		}
+		if len(name) > 10 {
+			return "", fmt.Errorf("name %s is longer than 10 characters", name)
+		}
+		return name, nil
+	})
+	if err != nil {
+		return err
+	}
+	_, err = w.ask("description", "Description", output.StrValue(defaults.Description), nil)
+	if err != nil {
+		return err
+	}
+
+	partitions := inputValues(sc, output.ClusterInputPartitions)
+	_, err = w.choose("partition", "Partition", partitions, nil, firstOr(output.StrValue(defaults.PartitionID), partitions), false)
+	if err != nil {
+		return err
+	}
+	_, err = w.choose("purpose", "Purpose, an SLA is only given on production clusters", []string{"production", "development", "evaluation"}, nil, output.StrValue(defaults.Purpose), false)
+	if err != nil {
+		return err
+	}
+	versions := inputValues(sc, output.ClusterInputVersions)
+	defaultVersion := ""
+	if defaults.Kubernetes != nil {
+		defaultVersion = output.StrValue(defaults.Kubernetes.Version)
+	}
+	_, err = w.choose("version", "Kubernetes version", versions, nil, firstOr(defaultVersion, versions), false)
+	if err != nil {
+		return err
+	}
+
+	worker := &models.V1Worker{}
+	if len(defaults.Workers) > 0 {
+		worker = defaults.Workers[0]
+	}
+	workers := "the workers"
+	if len(defaults.Workers) > 1 {
+		workers = fmt.Sprintf("the workers, a change applies to all %d worker groups", len(defaults.Workers))
+	}
+	w.unchanged["machinetype"] = output.StrValue(worker.MachineType)
+	w.unchanged["machineimage"] = machineImageFlag(worker.MachineImage)
+	w.unchanged["minsize"] = strconv.Itoa(int(int32Value(worker.Minimum, 0)))
+	w.unchanged["maxsize"] = strconv.Itoa(int(int32Value(worker.Maximum, 0)))
+	machineTypes := inputValues(sc, output.ClusterInputMachineTypes)
+	_, err = w.choose("machinetype", "Machine type of "+workers, machineTypes, nil, firstOr(output.StrValue(worker.MachineType), machineTypes), false)
+	if err != nil {
+		return err
+	}
+	_, err = w.choose("machineimage", "Machine image of "+workers+", empty for the default image", inputValues(sc, output.ClusterInputImages), nil, machineImageFlag(worker.MachineImage), true)
+	if err != nil {
+		return err
+	}
+	minsize, err := w.askInt("minsize", "Minimal number of "+workers, int32Value(worker.Minimum, 1), 1)
+	if err != nil {
+		return err
+	}
+	maxsize := int32Value(worker.Maximum, minsize)
+	if maxsize < minsize {
+		maxsize = minsize
+	}
+	_, err = w.askInt("maxsize", "Maximal number of "+workers, maxsize, minsize)
+	if err != nil {
+		return err
+	}
+
+	firewallTypes := inputValues(sc, output.ClusterInputFirewallTypes)
+	_, err = w.choose("firewalltype", "Machine type of the firewall", firewallTypes, nil, firstOr(output.StrValue(defaults.FirewallSize), firewallTypes), false)
+	if err != nil {
+		return err
+	}
+	_, err = w.choose("firewallimage", "Machine image of the firewall, empty for the default image", inputValues(sc, output.ClusterInputFirewallImages), nil, output.StrValue(defaults.FirewallImage), true)
+	if err != nil {
+		return err
+	}
+
+	networks, err := w.chooseMany("external-networks", "External networks", inputValues(sc, output.ClusterInputNetworks), defaults.AdditionalNetworks)
+	if err != nil {
+		return err
+	}
+	err = w.askEgress(projectID, networks, defaults.EgressRules)
+	if err != nil {
+		return err
+	}
+
+	timezone := viper.GetString("maintenance-timezone")
+	defaultBegin, defaultEnd, err := maintenanceDefaults(defaults.Maintenance, timezone)
+	if err != nil {
+		return err
+	}
+	w.unchanged["maintenance-begin"] = defaultBegin
+	w.unchanged["maintenance-end"] = defaultEnd
+	begin, err := w.ask("maintenance-begin", fmt.Sprintf("Begin of the daily maintenance time window in %s (hh:mm)", timezone), defaultBegin, func(t string) (string, error) {
+		_, err := time.Parse("15:04", t)
+		if err != nil {
+			return "", fmt.Errorf("invalid time of day:%s, must be in the form hh:mm", t)
+		}
+		return t, nil
+	})
+	if err != nil {
+		return err
+	}
+	end, err := w.ask("maintenance-end", fmt.Sprintf("End of the daily maintenance time window in %s (hh:mm)", timezone), defaultEnd, func(t string) (string, error) {
+		_, _, err := helper.MaintenanceTimeWindow(begin, t, timezone)
+		return t, err
+	})
+	if err != nil {
+		return err
+	}
+	// the maintenance flags replace the whole maintenance of the template, so a changed begin or end needs both of them
+	if w.asked["maintenance-begin"] && !w.asked["maintenance-end"] {
+		w.answer("maintenance-end", end)
+	}
+	if w.asked["maintenance-end"] && !w.asked["maintenance-begin"] {
+		w.answer("maintenance-begin", begin)
+	}
+
+	allowPrivileged := false
+	if defaults.Kubernetes != nil && defaults.Kubernetes.AllowPrivilegedContainers != nil {
+		allowPrivileged = *defaults.Kubernetes.AllowPrivilegedContainers
+	}
+	allowPrivileged, err = w.confirm("Allow privileged containers?", allowPrivileged)
+	if err != nil {
+		return err
+	}
+	w.set("allowprivileged", allowPrivileged)
+	if allowPrivileged {
+		w.args = append(w.args, "--allowprivileged")
+	}
+	return nil
+}
+
+// askEgress asks for the static ips of the project to use for the egress traffic of each of the given networks
+func (w *clusterWizard) askEgress(projectID string, networks []string, defaults []*models.V1EgressRule) error {
+	var rules []string
+	for _, network := range networks {
+		networkID := network
+		staticType := "static"
+		params := ip.NewFindIPsParams()
+		params.SetBody(&models.V1IPFindRequest{
+			ProjectID: &projectID,
+			NetworkID: &networkID,
+			Type:      &staticType,
+		})
+		resp, err := cloud.IP.FindIPs(params, nil)
+		if err != nil {
+			return err
+		}
+		var ips []string
+		labels := map[string]string{}
+		for _, i := range resp.Payload {
+			ips = append(ips, output.StrValue(i.Ipaddress))
+			labels[output.StrValue(i.Ipaddress)] = i.Name
+		}
+		if len(ips) == 0 {
+			fmt.Fprintf(w.out, "Project %s has no static ips in network %s for egress traffic, they can be allocated with cloudctl ip static.\n", projectID, network)
+			continue
+		}
+		sort.Strings(ips)
+		var defaultIPs []string
+		for _, r := range defaults {
+			if output.StrValue(r.NetworkID) == network {
+				defaultIPs = append(defaultIPs, r.IPs...)
+			}
+		}
+		selected, err := w.selectMany("egress", fmt.Sprintf("Static egress ips in network %s", network), ips, labels, defaultIPs)
+		if err != nil {
+			return err
+		}
+		for _, s := range selected {
+			rules = append(rules, network+":"+s)
+		}
+	}
+	w.set("egress", rules)
+	for _, r := range rules {
+		w.args = append(w.args, "--egress", r)
+	}
+	return nil
+}
+
+// ask asks for a value of the given flag until parse accepts it, an empty answer takes the default
+func (w *clusterWizard) ask(flag, question, def string, parse func(string) (string, error)) (string, error) {
+	for {
+		if def != "" {
+			fmt.Fprintf(w.out, "%s [%s]: ", question, def)
+		} else {
+			fmt.Fprintf(w.out, "%s: ", question)
+		}
+		answer, err := w.readLine()
+		if err != nil {
+			return "", err
+		}
+		if answer == "" {
+			answer = def
+		}
+		if parse != nil {
+			answer, err = parse(answer)
+			if err != nil {
+				fmt.Fprintln(w.out, err)
+				continue
+			}
+		}
+		if v, ok := w.unchanged[flag]; !ok || v != answer {
+			w.answer(flag, answer)
+		}
+		return answer, nil
+	}
+}
+
+// answer takes the answer as value of the flag and adds it to the command line
+func (w *clusterWizard) answer(flag, answer string) {
+	w.set(flag, answer)
+	if answer != "" {
+		w.args = append(w.args, "--"+flag, answer)
+	}
+}
+
+// askInt asks for a number of the given flag which is at least minimum
+func (w *clusterWizard) askInt(flag, question string, def, minimum int32) (int32, error) {
+	var value int32
+	_, err := w.ask(flag, question, strconv.Itoa(int(def)), func(answer string) (string, error) {
+		i, err := strconv.ParseInt(answer, 10, 32)
+		if err != nil {
+			return "", fmt.Errorf("%s is not a number", answer)
+		}
+		if int32(i) < minimum {
+			return "", fmt.Errorf("%s must be at least %d", flag, minimum)
+		}
+		value = int32(i)
+		return answer, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if w.asked[flag] {
+		w.set(flag, value)
+	}
+	return value, nil
+}
+
+// choose asks for one of the choices of the given flag by number or value, if optional the answer can be empty
+func (w *clusterWizard) choose(flag, question string, choices []string, labels map[string]string, def string, optional bool) (string, error) {
+	if len(choices) == 0 && !optional {
+		return "", fmt.Errorf("there are no choices for %s", flag)
+	}
+	w.printChoices(question, choices, labels)
+	return w.ask(flag, question, def, func(answer string) (string, error) {
+		if answer == "" {
+			if optional {
+				return "", nil
+			}
+			return "", fmt.Errorf("%s is required", flag)
+		}
+		return w.choice(flag, answer, choices)
+	})
+}
+
+// chooseMany asks for any number of the choices of the given flag
+func (w *clusterWizard) chooseMany(flag, question string, choices []string, defaults []string) ([]string, error) {
+	selected, err := w.selectMany(flag, question, choices, nil, defaults)
+	if err != nil {
+		return nil, err
+	}
+	w.set(flag, selected)
+	if len(selected) > 0 {
+		w.args = append(w.args, "--"+flag, strings.Join(selected, ","))
+	}
+	return selected, nil
+}
+
+// selectMany asks for a comma separated list of choices by number or value, "-" selects none of them
+func (w *clusterWizard) selectMany(input, question string, choices []string, labels map[string]string, defaults []string) ([]string, error) {
+	w.printChoices(question, choices, labels)
+	def := strings.Join(defaults, ",")
+	if def == "" {
+		def = "-"
+	}
+	for {
+		fmt.Fprintf(w.out, "%s, comma separated, - for none [%s]: ", question, def)
+		answer, err := w.readLine()
+		if err != nil {
+			return nil, err
+		}
+		if answer == "" {
+			answer = def
+		}
+		if answer == "-" {
+			return []string{}, nil
+		}
+		var (
+			selected []string
+			invalid  error
+		)
+		for _, a := range strings.Split(answer, ",") {
+			c, err := w.choice(input, strings.TrimSpace(a), choices)
+			if err != nil {
+				invalid = err
+				break
+			}
+			selected = append(selected, c)
+		}
+		if invalid != nil {
+			fmt.Fprintln(w.out, invalid)
+			continue
+		}
+		return selected, nil
+	}
+}
+
+// choice returns the choice given by its number or value
+func (w *clusterWizard) choice(input, answer string, choices []string) (string, error) {
+	if i, err := strconv.Atoi(answer); err == nil && i >= 1 && i <= len(choices) {
+		return choices[i-1], nil
+	}
+	return answer, checkConstraint(input, answer, choices)
+}
+
+func (w *clusterWizard) printChoices(question string, choices []string, labels map[string]string) {
+	fmt.Fprintf(w.out, "\n%s:\n", question)
+	for i, c := range choices {
+		if label := labels[c]; label != "" {
+			c += "  " + label
+		}
+		fmt.Fprintf(w.out, "  %d) %s\n", i+1, c)
+	}
+}
+
+func (w *clusterWizard) confirm(question string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	for {
+		fmt.Fprintf(w.out, "%s [%s]: ", question, hint)
+		answer, err := w.readLine()
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Fprintln(w.out, "please answer y or n")
+	}
+}
+
+func (w *clusterWizard) readLine() (string, error) {
+	line, err := w.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("aborted, no answer given")
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// set takes the answer as value of the flag
+func (w *clusterWizard) set(flag string, value interface{}) {
+	viper.Set(flag, value)
+	w.asked[flag] = true
+}
+
+// commandLine returns the cluster create command line with the answers and the other flags which were given
+func (w *clusterWizard) commandLine(flags *pflag.FlagSet) string {
+	args := append([]string{"cloudctl", "cluster", "create"}, w.args...)
+	flags.Visit(func(f *pflag.Flag) {
+		if w.asked[f.Name] || f.Name == "interactive" {
+			return
+		}
+		if s, ok := f.Value.(pflag.SliceValue); ok {
+			for _, v := range s.GetSlice() {
+				args = append(args, "--"+f.Name, v)
+			}
+			return
+		}
+		if f.Value.Type() == "bool" {
+			args = append(args, "--"+f.Name+"="+f.Value.String())
+			return
+		}
+		args = append(args, "--"+f.Name, f.Value.String())
+	})
+	return helper.ShellJoin(args)
+}
+
+// inputValues returns the possible values of the given kind of cluster input in the order of cluster inputs
+func inputValues(sc *models.V1ShootConstraints, kind string) []string {
+	inputs, err := output.NewClusterInputs(sc, kind)
+	if err != nil {
+		return nil
+	}
+	var values []string
+	for _, i := range inputs {
+		values = append(values, i.Value)
+	}
+	return values
+}
+
+func firstOr(value string, values []string) string {
+	if value != "" || len(values) == 0 {
+		return value
+	}
+	return values[0]
+}
+
+func int32Value(i *int32, def int32) int32 {
+	if i == nil {
+		return def
+	}
+	return *i
+}
+
+// maintenanceDefaults returns begin and end of the maintenance time window of the request as time of day in the given timezone
+func maintenanceDefaults(maintenance *models.V1Maintenance, timezone string) (string, string, error) {
+	begin := viper.GetString("maintenance-begin")
+	end := viper.GetString("maintenance-end")
+	if maintenance == nil || maintenance.TimeWindow == nil || maintenance.TimeWindow.Begin == nil || maintenance.TimeWindow.End == nil {
+		return begin, end, nil
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return "", "", fmt.Errorf("unknown timezone:%s %w", timezone, err)
+	}
+	b, err := helper.MaintenanceTimeIn(*maintenance.TimeWindow.Begin, loc)
+	if err != nil {
+		return "", "", err
+	}
+	e, err := helper.MaintenanceTimeIn(*maintenance.TimeWindow.End, loc)
+	if err != nil {
+		return "", "", err
+	}
+	return b.Format("15:04"), e.Format("15:04"), nil
+}
+
+// machineImageFlag returns the machine image in the form of the machineimage flag, empty if no image is given
+func machineImageFlag(image *models.V1MachineImage) string {
+	if image == nil || image.Name == nil || *image.Name == "" {
+		return ""
+	}
+	return *image.Name + "-" + output.StrValue(image.Version)
+}
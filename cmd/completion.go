@@ -84,6 +84,18 @@ func contextListCompletion() ([]string, cobra.ShellCompDirective) {
 	return names, cobra.ShellCompDirectiveDefault
 }
 
+func clusterTemplateListCompletion() ([]string, cobra.ShellCompDirective) {
+	templates, err := clusterTemplates()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var names []string
+	for _, t := range templates {
+		names = append(names, t.Name)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
 func clusterListCompletion() ([]string, cobra.ShellCompDirective) {
 	request := cluster.NewListClustersParams()
 	response, err := cloud.Cluster.ListClusters(request, nil)
@@ -105,10 +117,24 @@ func clusterMachineListCompletion(clusterID string) ([]string, cobra.ShellCompDi
 		return nil, cobra.ShellCompDirectiveError
 	}
 	var machines []string
-	for _, m := range shoot.Payload.Machines {
+	for _, m := range append(shoot.Payload.Machines, shoot.Payload.Firewalls...) {
 		machines = append(machines, *m.ID)
 	}
-	return machines, cobra.ShellCompDirectiveDefault
+	return machines, cobra.ShellCompDirectiveNoFileComp
+}
+
+func clusterFirewallListCompletion(clusterID string) ([]string, cobra.ShellCompDirective) {
+	findRequest := cluster.NewFindClusterParams()
+	findRequest.SetID(clusterID)
+	shoot, err := cloud.Cluster.FindCluster(findRequest, nil)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var firewalls []string
+	for _, fw := range shoot.Payload.Firewalls {
+		firewalls = append(firewalls, *fw.ID)
+	}
+	return firewalls, cobra.ShellCompDirectiveNoFileComp
 }
 
 func projectListCompletion() ([]string, cobra.ShellCompDirective) {
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
 
@@ -95,8 +96,7 @@ func contextSet(args []string) error {
 	if !ok {
 		return fmt.Errorf("context %s not found", defaultCtxName)
 	}
-	ctxs.CurrentContext = defaultCtxName
-	return writeContexts(ctxs)
+	return writeCurrentContext(defaultCtxName, ctxs.PreviousContext)
 }
 
 func previous() error {
@@ -108,10 +108,7 @@ func previous() error {
 	if prev == "" {
 		prev = ctxs.CurrentContext
 	}
-	curr := ctxs.CurrentContext
-	ctxs.PreviousContext = curr
-	ctxs.CurrentContext = prev
-	return writeContexts(ctxs)
+	return writeCurrentContext(prev, ctxs.CurrentContext)
 }
 
 func contextList() error {
@@ -145,12 +142,50 @@ func getContexts() (*api.Contexts, error) {
 	return &ctxs, err
 }
 
-func writeContexts(ctxs *api.Contexts) error {
+// writeCurrentContext sets the current and previous context in the config, the rest of the config is kept as it is
+func writeCurrentContext(current, previous string) error {
 	cfgFile := viper.GetViper().ConfigFileUsed()
 	fmt.Printf("update config:%s\n", cfgFile)
-	c, err := yaml.Marshal(ctxs)
+	content, err := ioutil.ReadFile(cfgFile)
 	if err != nil {
 		return err
 	}
-	return ioutil.WriteFile(cfgFile, c, 0644)
+	var config yaml.Node
+	err = yaml.Unmarshal(content, &config)
+	if err != nil {
+		return err
+	}
+	if config.Kind != yaml.DocumentNode || len(config.Content) != 1 || config.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("config:%s does not contain a yaml mapping", cfgFile)
+	}
+	setYAMLString(config.Content[0], "current", current)
+	setYAMLString(config.Content[0], "previous", previous)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	err = encoder.Encode(&config)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(cfgFile, buf.Bytes(), 0644)
+}
+
+// setYAMLString sets the value of the key in the given mapping, the key is added if it is missing and the value is not empty
+func setYAMLString(mapping *yaml.Node, key, value string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			// the style of the old value, e.g. the quotes of an empty string, is not kept
+			mapping.Content[i+1].Style = 0
+			mapping.Content[i+1].SetString(value)
+			return
+		}
+	}
+	if value == "" {
+		return
+	}
+	var k, v yaml.Node
+	k.SetString(key)
+	v.SetString(value)
+	mapping.Content = append(mapping.Content, &k, &v)
 }
@@ -0,0 +1,454 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fi-ts/cloud-go/api/client/cluster"
+	"github.com/fi-ts/cloud-go/api/models"
+	"github.com/fi-ts/cloudctl/cmd/output"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+)
+
+var dashboardCmd = &cobra.Command{
+	Use:   "dashboard",
+	Short: "show a live updating dashboard of the clusters",
+	Long: `show a full screen dashboard of the clusters and their issues which is refreshed periodically.
+keys: up/down select, enter shows the details of a cluster, t filters by tenant, p filters by project, o changes the order,
+r reconciles and R retries the selected cluster after confirmation, esc goes back and q quits.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return dashboard()
+	},
+	PreRun: bindPFlags,
+}
+
+func init() {
+	dashboardCmd.Flags().String("tenant", "", "show clusters of given tenant")
+	dashboardCmd.Flags().String("project", "", "show clusters of given project")
+	dashboardCmd.Flags().Duration("interval", 10*time.Second, "interval in which the clusters are refreshed")
+	dashboardCmd.RegisterFlagCompletionFunc("project", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return projectListCompletion()
+	})
+}
+
+const (
+	// alternate screen without cursor and line wrap, long lines are cut at the edge of the terminal
+	dashboardEnterScreen = "\x1b[?1049h\x1b[?25l\x1b[?7l"
+	dashboardLeaveScreen = "\x1b[?7h\x1b[?25h\x1b[?1049l"
+	dashboardClearScreen = "\x1b[H\x1b[2J"
+	dashboardHighlight   = "\x1b[7m"
+	dashboardReset       = "\x1b[0m"
+)
+
+// the orders the dashboard cycles through, the columns are the ones supported by --order of cluster ls
+var dashboardOrders = []string{"tenant,project,name", "project,name", "name"}
+
+type dashboardState struct {
+	tenant  string
+	project string
+	orders  []string
+	order   int
+
+	shoots  []*models.V1ClusterResponse
+	updated time.Time
+	err     error
+
+	// selected is the id of the selected cluster, detail is set if the selected cluster is shown in detail
+	selected string
+	detail   bool
+	offset   int
+	// rows are the ids of the clusters in the order they are shown in the list
+	rows []string
+
+	status  string
+	input   *dashboardInput
+	confirm *dashboardConfirm
+	// operated receives the status of operations which were triggered in the background
+	operated chan string
+}
+
+// dashboardInput is a line of text entered by the user
+type dashboardInput struct {
+	label string
+	value string
+	apply func(value string)
+}
+
+// dashboardConfirm is a question which must be answered with y before run is executed
+type dashboardConfirm struct {
+	question string
+	run      func()
+}
+
+type dashboardFetch struct {
+	shoots []*models.V1ClusterResponse
+	err    error
+}
+
+func dashboard() error {
+	interval := viper.GetDuration("interval")
+	if interval <= 0 {
+		return fmt.Errorf("interval must be positive, got:%s", interval)
+	}
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return fmt.Errorf("dashboard requires a terminal")
+	}
+
+	d := &dashboardState{
+		tenant:   viper.GetString("tenant"),
+		project:  viper.GetString("project"),
+		orders:   dashboardOrders,
+		operated: make(chan string, 1),
+	}
+	if order := viper.GetString("order"); order != "" {
+		d.orders = append([]string{order}, dashboardOrders...)
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		fmt.Print(dashboardLeaveScreen)
+		_ = term.Restore(fd, state)
+	}()
+	fmt.Print(dashboardEnterScreen)
+
+	keys := make(chan string)
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			n, err := os.Stdin.Read(buf)
+			if err != nil {
+				close(keys)
+				return
+			}
+			keys <- string(buf[:n])
+		}
+	}()
+
+	fetched := make(chan dashboardFetch, 1)
+	fetch := func() {
+		go func() {
+			shoots, err := dashboardClusters()
+			fetched <- dashboardFetch{shoots: shoots, err: err}
+		}()
+	}
+	fetch()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		d.render(os.Stdout, fd)
+		select {
+		case key, ok := <-keys:
+			if !ok || d.handleKey(key) {
+				return nil
+			}
+		case f := <-fetched:
+			d.shoots, d.err, d.updated = f.shoots, f.err, time.Now()
+		case status := <-d.operated:
+			d.status = status
+			fetch()
+		case <-ticker.C:
+			fetch()
+		}
+	}
+}
+
+// dashboardClusters returns all clusters including their machines
+func dashboardClusters() ([]*models.V1ClusterResponse, error) {
+	boolTrue := true
+	request := cluster.NewListClustersParams().WithReturnMachines(&boolTrue)
+	response, err := cloud.Cluster.ListClusters(request, nil)
+	if err != nil {
+		return nil, err
+	}
+	return response.Payload, nil
+}
+
+// visible returns the clusters matching the filters
+func (d *dashboardState) visible() []*models.V1ClusterResponse {
+	var shoots []*models.V1ClusterResponse
+	for _, s := range d.shoots {
+		if d.tenant != "" && (s.Tenant == nil || *s.Tenant != d.tenant) {
+			continue
+		}
+		if d.project != "" && (s.ProjectID == nil || *s.ProjectID != d.project) {
+			continue
+		}
+		shoots = append(shoots, s)
+	}
+	return shoots
+}
+
+func (d *dashboardState) selectedCluster() *models.V1ClusterResponse {
+	for _, s := range d.visible() {
+		if s.ID != nil && *s.ID == d.selected {
+			return s
+		}
+	}
+	return nil
+}
+
+// handleKey processes a key press and returns true if the dashboard should quit
+func (d *dashboardState) handleKey(key string) bool {
+	if key == "\x03" {
+		return true
+	}
+
+	if c := d.confirm; c != nil {
+		d.confirm = nil
+		d.status = "aborted"
+		if key == "y" || key == "Y" {
+			c.run()
+		}
+		return false
+	}
+
+	if in := d.input; in != nil {
+		switch key {
+		case "\r", "\n":
+			d.input = nil
+			in.apply(in.value)
+		case "\x1b":
+			d.input = nil
+		case "\x7f", "\b":
+			if len(in.value) > 0 {
+				in.value = in.value[:len(in.value)-1]
+			}
+		default:
+			if !strings.HasPrefix(key, "\x1b") {
+				in.value += key
+			}
+		}
+		return false
+	}
+
+	d.status = ""
+	switch key {
+	case "q":
+		return true
+	case "\x1b":
+		d.detail = false
+		d.offset = 0
+	case "\r", "\n":
+		if d.selectedCluster() != nil {
+			d.detail = true
+			d.offset = 0
+		}
+	case "\x1b[A", "k":
+		d.move(-1)
+	case "\x1b[B", "j":
+		d.move(1)
+	case "\x1b[5~":
+		d.move(-10)
+	case "\x1b[6~":
+		d.move(10)
+	case "t":
+		d.input = &dashboardInput{label: "tenant", value: d.tenant, apply: func(v string) { d.tenant = strings.TrimSpace(v) }}
+	case "p":
+		d.input = &dashboardInput{label: "project", value: d.project, apply: func(v string) { d.project = strings.TrimSpace(v) }}
+	case "o":
+		d.order = (d.order + 1) % len(d.orders)
+	case "r":
+		d.reconcile(nil)
+	case "R":
+		retry := "retry"
+		d.reconcile(&retry)
+	}
+	return false
+}
+
+// move moves the selection in the list, or scrolls the details
+func (d *dashboardState) move(delta int) {
+	if d.detail {
+		d.offset += delta
+		if d.offset < 0 {
+			d.offset = 0
+		}
+		return
+	}
+	if len(d.rows) == 0 {
+		return
+	}
+	i := 0
+	for j, id := range d.rows {
+		if id == d.selected {
+			i = j
+			break
+		}
+	}
+	i += delta
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(d.rows) {
+		i = len(d.rows) - 1
+	}
+	d.selected = d.rows[i]
+}
+
+// reconcile triggers the operation on the selected cluster, after the same confirmation as cluster reconcile with a selector.
+// the operation runs in the background, its result is reported through operated.
+func (d *dashboardState) reconcile(operation *string) {
+	shoot := d.selectedCluster()
+	if shoot == nil {
+		return
+	}
+	verb := "reconcile"
+	if operation != nil {
+		verb = *operation
+	}
+	id, name := output.StrValue(shoot.ID), output.StrValue(shoot.Name)
+	run := func() {
+		d.status = fmt.Sprintf("%s of %s requested...", verb, name)
+		go func() {
+			result, err := reconcileClusterOperation(id, operation)
+			if err != nil {
+				d.operated <- fmt.Sprintf("%s of %s failed: %v", verb, name, err)
+				return
+			}
+			d.operated <- fmt.Sprintf("%s of %s triggered: %s", verb, name, output.LastOperationString(result))
+		}()
+	}
+	if viper.GetBool("yes-i-really-mean-it") {
+		run()
+		return
+	}
+	d.confirm = &dashboardConfirm{
+		question: fmt.Sprintf("%s (%s): %s Are you sure? (y/n)", *shoot.Name, *shoot.ID, clusterOperationConfirmation(verb, 1)),
+		run:      run,
+	}
+}
+
+func (d *dashboardState) render(w *os.File, fd int) {
+	width, height, err := term.GetSize(fd)
+	if err != nil || width <= 0 || height <= 0 {
+		width, height = 80, 24
+	}
+	// title, status and help line
+	space := height - 3
+	if space < 1 {
+		space = 1
+	}
+
+	filter := []string{fmt.Sprintf("order:%s", d.orders[d.order])}
+	if d.tenant != "" {
+		filter = append(filter, "tenant:"+d.tenant)
+	}
+	if d.project != "" {
+		filter = append(filter, "project:"+d.project)
+	}
+	updated := "loading..."
+	if !d.updated.IsZero() {
+		updated = "updated " + d.updated.Format("15:04:05")
+	}
+	title := fmt.Sprintf("cloudctl dashboard  %s  %s", strings.Join(filter, "  "), updated)
+
+	var body []string
+	help := "↑/↓ select  enter details  t tenant  p project  o order  r reconcile  R retry  q quit"
+	switch {
+	case d.err != nil:
+		body = []string{"", fmt.Sprintf("unable to list clusters: %v", d.err)}
+	case d.detail && d.selectedCluster() != nil:
+		var buf bytes.Buffer
+		_ = output.DescribeShoot(&buf, d.selectedCluster())
+		body = strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+		if d.offset > len(body)-space {
+			d.offset = len(body) - space
+		}
+		if d.offset < 0 {
+			d.offset = 0
+		}
+		body = body[d.offset:]
+		help = "↑/↓ scroll  r reconcile  R retry  esc back  q quit"
+	default:
+		d.detail = false
+		body = d.renderList(space)
+	}
+	if len(body) > space {
+		body = body[:space]
+	}
+
+	status := d.status
+	switch {
+	case d.confirm != nil:
+		status = d.confirm.question
+	case d.input != nil:
+		status = fmt.Sprintf("filter by %s (empty for all, enter to apply, esc to cancel): %s", d.input.label, d.input.value)
+	}
+
+	var out strings.Builder
+	out.WriteString(dashboardClearScreen)
+	out.WriteString(dashboardHighlight + padRight(title, width) + dashboardReset + "\r\n")
+	for _, line := range body {
+		out.WriteString(line + "\r\n")
+	}
+	for i := len(body); i < space; i++ {
+		out.WriteString("\r\n")
+	}
+	out.WriteString(status + "\r\n")
+	out.WriteString(dashboardHighlight + padRight(help, width) + dashboardReset)
+	fmt.Fprint(w, out.String())
+}
+
+// renderList renders the table of the clusters with the selected cluster highlighted and scrolled into view
+func (d *dashboardState) renderList(space int) []string {
+	shoots := d.visible()
+	d.rows = nil
+	if len(shoots) == 0 {
+		return []string{"", "no clusters found"}
+	}
+	var buf bytes.Buffer
+	// the printer sorts the clusters in place, so rows and clusters have the same order afterwards
+	p := output.NewTablePrinterTo(&buf, "table", d.orders[d.order])
+	_ = p.Print(output.ShootIssuesResponses(shoots))
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	// a single cluster is followed by its issues, they are shown in the details
+	if len(shoots) == 1 && len(lines) > 2 {
+		lines = lines[:2]
+	}
+	if len(lines) != len(shoots)+1 {
+		return lines
+	}
+
+	selected := 0
+	for i, s := range shoots {
+		d.rows = append(d.rows, *s.ID)
+		if *s.ID == d.selected {
+			selected = i
+		}
+	}
+	d.selected = *shoots[selected].ID
+
+	rows := space - 1
+	if selected < d.offset {
+		d.offset = selected
+	}
+	if rows > 0 && selected >= d.offset+rows {
+		d.offset = selected - rows + 1
+	}
+	body := []string{lines[0]}
+	for i := d.offset; i < len(shoots) && len(body) <= rows; i++ {
+		line := lines[i+1]
+		if i == selected {
+			line = dashboardHighlight + line + dashboardReset
+		}
+		body = append(body, line)
+	}
+	return body
+}
+
+func padRight(s string, width int) string {
+	if n := len([]rune(s)); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
@@ -2,6 +2,7 @@ package helper
 
 import (
 	"bufio"
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -9,6 +10,7 @@ import (
 	"math"
 	"os"
 	"os/exec"
+	"regexp"
 	"strings"
 	"time"
 
@@ -127,7 +129,9 @@ func ReadFrom(from string, data interface{}, f func(target interface{})) error {
 	return nil
 }
 
-// Edit a yaml response from getFunc in place and call updateFunc after save
+// Edit a yaml response from getFunc in place and call updateFunc after save.
+// If updateFunc fails, the editor is reopened with the error shown as comment on top of the edited content,
+// closing the editor without further changes aborts the edit and returns the error.
 func Edit(id string, getFunc func(id string) ([]byte, error), updateFunc func(filename string) error) error {
 	editor, ok := os.LookupEnv("EDITOR")
 	if !ok {
@@ -143,19 +147,59 @@ func Edit(id string, getFunc func(id string) ([]byte, error), updateFunc func(fi
 	if err != nil {
 		return err
 	}
-	err = ioutil.WriteFile(tmpfile.Name(), content, os.ModePerm)
-	if err != nil {
-		return err
+
+	var updateErr error
+	for {
+		err = ioutil.WriteFile(tmpfile.Name(), content, os.ModePerm)
+		if err != nil {
+			return err
+		}
+		editCommand := exec.Command(editor, tmpfile.Name())
+		editCommand.Stdout = os.Stdout
+		editCommand.Stdin = os.Stdin
+		editCommand.Stderr = os.Stderr
+		err = editCommand.Run()
+		if err != nil {
+			return err
+		}
+		edited, err := ioutil.ReadFile(tmpfile.Name())
+		if err != nil {
+			return err
+		}
+		if updateErr != nil && bytes.Equal(edited, content) {
+			return fmt.Errorf("edit aborted, no changes made: %w", updateErr)
+		}
+
+		updateErr = updateFunc(tmpfile.Name())
+		if updateErr == nil {
+			return nil
+		}
+		content = append(editErrorComment(updateErr), stripEditErrorComment(edited)...)
 	}
-	editCommand := exec.Command(editor, tmpfile.Name())
-	editCommand.Stdout = os.Stdout
-	editCommand.Stdin = os.Stdin
-	editCommand.Stderr = os.Stderr
-	err = editCommand.Run()
-	if err != nil {
-		return err
+}
+
+const editErrorCommentPrefix = "# edit error: "
+
+// editErrorComment renders the given error as yaml comment block which is shown on top of the edited content
+func editErrorComment(err error) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(editErrorCommentPrefix + "please correct the content below or exit without changes to abort\n")
+	for _, line := range strings.Split(err.Error(), "\n") {
+		buf.WriteString(editErrorCommentPrefix + line + "\n")
+	}
+	return buf.Bytes()
+}
+
+// stripEditErrorComment removes a previously added error comment block
+func stripEditErrorComment(content []byte) []byte {
+	for bytes.HasPrefix(content, []byte(editErrorCommentPrefix)) {
+		i := bytes.IndexByte(content, '\n')
+		if i < 0 {
+			return nil
+		}
+		content = content[i+1:]
 	}
-	return updateFunc(tmpfile.Name())
+	return content
 }
 
 func LabelsToMap(labels []string) (map[string]string, error) {
@@ -169,3 +213,81 @@ func LabelsToMap(labels []string) (map[string]string, error) {
 	}
 	return labelMap, nil
 }
+
+// PrependMarkedBlock puts block between the begin and end marker lines at the beginning of content,
+// a block with the same markers which is already present is removed.
+func PrependMarkedBlock(content, begin, end, block string) string {
+	marked := begin + "\n" + strings.TrimRight(block, "\n") + "\n" + end + "\n"
+	start := strings.Index(content, begin+"\n")
+	if start >= 0 {
+		stop := strings.Index(content[start:], end+"\n")
+		if stop >= 0 {
+			content = content[:start] + strings.TrimLeft(content[start+stop+len(end)+1:], "\n")
+		}
+	}
+	if content == "" {
+		return marked
+	}
+	return marked + "\n" + content
+}
+
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// ShellQuote quotes the given argument for a posix shell if it contains other than safe characters
+func ShellQuote(s string) string {
+	if shellSafe.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// ShellJoin joins the given arguments to a command line for a posix shell, every argument is quoted if needed
+func ShellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = ShellQuote(a)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// ClosestMatch returns the candidate with the smallest edit distance to value,
+// empty if no candidate is similar enough to be a typo of value.
+func ClosestMatch(value string, candidates []string) string {
+	closest := ""
+	best := len(value)/3 + 2
+	for _, c := range candidates {
+		d := levenshtein(strings.ToLower(value), strings.ToLower(c))
+		if d < best {
+			best = d
+			closest = c
+		}
+	}
+	return closest
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current := make([]int, len(rb)+1)
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = previous[j-1] + cost
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+		previous = current
+	}
+	return previous[len(rb)]
+}
@@ -7,7 +7,13 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-func EnrichKubeconfigTpl(tpl string, authContext *auth.AuthContext) ([]byte, error) {
+// ExecCredentialAPIVersion is the api version of the exec credentials cloudctl token provides
+const ExecCredentialAPIVersion = "client.authentication.k8s.io/v1beta1"
+
+// EnrichKubeconfigTpl adds the user of the given auth context and a context for the cluster of the template,
+// if contextName is empty the context is named <user>@<cluster>.
+// If execCommand is given, the user gets its credentials by calling this command instead of the id token of the auth context.
+func EnrichKubeconfigTpl(tpl string, authContext *auth.AuthContext, contextName string, execCommand []string) ([]byte, error) {
 	cfg := make(map[interface{}]interface{})
 	err := yaml.Unmarshal([]byte(tpl), cfg)
 	if err != nil {
@@ -24,12 +30,20 @@ func EnrichKubeconfigTpl(tpl string, authContext *auth.AuthContext) ([]byte, err
 
 	userName := authContext.User
 	clusterName := clusterNames[0]
-	contextName := fmt.Sprintf("%s@%s", userName, clusterName)
+	if contextName == "" {
+		contextName = fmt.Sprintf("%s@%s", userName, clusterName)
+	}
 
-	// merge with current user credentials
-	err = auth.AddUser(cfg, *authContext)
-	if err != nil {
-		return nil, err
+	if len(execCommand) > 0 {
+		// the exec user must not replace the oidc user of cloudctl login when merged into the same kubeconfig
+		userName = fmt.Sprintf("%s-exec", userName)
+		addExecUser(cfg, userName, execCommand)
+	} else {
+		// merge with current user credentials
+		err = auth.AddUser(cfg, *authContext)
+		if err != nil {
+			return nil, err
+		}
 	}
 	err = auth.AddContext(cfg, contextName, clusterName, userName)
 	if err != nil {
@@ -44,3 +58,138 @@ func EnrichKubeconfigTpl(tpl string, authContext *auth.AuthContext) ([]byte, err
 
 	return mergedKubeconfig, nil
 }
+
+// addExecUser adds or replaces the user with the given name by a user calling the given exec credential plugin command
+func addExecUser(cfg map[interface{}]interface{}, userName string, execCommand []string) {
+	args := make([]interface{}, 0, len(execCommand)-1)
+	for _, arg := range execCommand[1:] {
+		args = append(args, arg)
+	}
+	user := map[string]interface{}{
+		"name": userName,
+		"user": map[string]interface{}{
+			"exec": map[string]interface{}{
+				"apiVersion":  ExecCredentialAPIVersion,
+				"command":     execCommand[0],
+				"args":        args,
+				"installHint": "cloudctl is required to authenticate to this cluster, download it from https://github.com/fi-ts/cloudctl/releases",
+			},
+		},
+	}
+
+	users, _ := cfg["users"].([]interface{})
+	for i := range users {
+		if kubeconfigEntryName(users[i]) == userName {
+			users[i] = user
+			return
+		}
+	}
+	cfg["users"] = append(users, user)
+}
+
+// KubeconfigRefreshToken returns the refresh token of the oidc user with the given name, empty if there is none
+func KubeconfigRefreshToken(cfg map[interface{}]interface{}, userName string) string {
+	config := authProviderConfig(kubeconfigUser(cfg, userName))
+	token, _ := config["refresh-token"].(string)
+	return token
+}
+
+// SetKubeconfigTokens replaces the id and refresh token of the oidc user with the given name
+func SetKubeconfigTokens(cfg map[interface{}]interface{}, userName, idToken, refreshToken string) error {
+	config := authProviderConfig(kubeconfigUser(cfg, userName))
+	if config == nil {
+		return fmt.Errorf("user %s with oidc auth-provider not found in kubeconfig", userName)
+	}
+	config["id-token"] = idToken
+	config["refresh-token"] = refreshToken
+	return nil
+}
+
+func kubeconfigUser(cfg map[interface{}]interface{}, userName string) interface{} {
+	users, _ := cfg["users"].([]interface{})
+	for _, u := range users {
+		if kubeconfigEntryName(u) == userName {
+			return u
+		}
+	}
+	return nil
+}
+
+// MergeKubeconfig upserts the clusters, users and contexts of source into target by their name,
+// all other entries of target stay untouched. The current context of target is only changed if setCurrentContext is true.
+func MergeKubeconfig(target, source map[interface{}]interface{}, setCurrentContext bool) error {
+	for _, key := range []string{"clusters", "users", "contexts"} {
+		sourceEntries, ok := source[key].([]interface{})
+		if !ok {
+			continue
+		}
+		targetEntries, _ := target[key].([]interface{})
+		for _, entry := range sourceEntries {
+			name := kubeconfigEntryName(entry)
+			if name == "" {
+				return fmt.Errorf("%s of kubeconfig contain an entry without name", key)
+			}
+			found := false
+			for i := range targetEntries {
+				if kubeconfigEntryName(targetEntries[i]) != name {
+					continue
+				}
+				if key == "users" {
+					keepRefreshToken(targetEntries[i], entry)
+				}
+				targetEntries[i] = entry
+				found = true
+				break
+			}
+			if !found {
+				targetEntries = append(targetEntries, entry)
+			}
+		}
+		target[key] = targetEntries
+	}
+	if setCurrentContext {
+		target["current-context"] = source["current-context"]
+	}
+	return nil
+}
+
+// kubeconfigEntryName returns the name of a cluster, user or context entry of a kubeconfig
+func kubeconfigEntryName(entry interface{}) string {
+	m := kubeconfigMap(entry)
+	if m == nil {
+		return ""
+	}
+	name, _ := m["name"].(string)
+	return name
+}
+
+// keepRefreshToken copies the refresh token of an existing oidc user to the user replacing it,
+// the user of a cluster kubeconfig has the same name as the user of cloudctl login which must stay able to refresh its token.
+func keepRefreshToken(existing, replacement interface{}) {
+	existingConfig := authProviderConfig(existing)
+	replacementConfig := authProviderConfig(replacement)
+	if existingConfig == nil || replacementConfig == nil {
+		return
+	}
+	if _, ok := replacementConfig["refresh-token"]; ok {
+		return
+	}
+	if token, ok := existingConfig["refresh-token"]; ok {
+		replacementConfig["refresh-token"] = token
+	}
+}
+
+func authProviderConfig(user interface{}) map[string]interface{} {
+	u := kubeconfigMap(kubeconfigMap(user)["user"])
+	p := kubeconfigMap(u["auth-provider"])
+	return kubeconfigMap(p["config"])
+}
+
+// kubeconfigMap returns the given value as map, nested maps of a kubeconfig parsed with yaml.v3 have string keys
+func kubeconfigMap(v interface{}) map[string]interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	return m
+}
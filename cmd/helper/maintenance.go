@@ -0,0 +1,81 @@
+package helper
+
+import (
+	"fmt"
+	"time"
+
+	// embed the timezone database, otherwise timezones can not be loaded on systems without one, e.g. windows
+	_ "time/tzdata"
+)
+
+const (
+	// maintenanceTimeFormat is the format of the begin and end of a maintenance time window in the api
+	maintenanceTimeFormat = "150405-0700"
+
+	// MaintenanceTimeWindowMinimum is the minimal duration of a maintenance time window
+	MaintenanceTimeWindowMinimum = 30 * time.Minute
+	// MaintenanceTimeWindowMaximum is the maximal duration of a maintenance time window
+	MaintenanceTimeWindowMaximum = 6 * time.Hour
+)
+
+// MaintenanceTimeWindow converts begin and end given as time of day (e.g. 02:00) in the given timezone
+// to the format of the api. A window which ends before it begins is considered to cross midnight.
+// The api only knows fixed offsets, so the offset the timezone has today is used.
+func MaintenanceTimeWindow(begin, end, timezone string) (string, string, error) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return "", "", fmt.Errorf("unknown timezone:%s %w", timezone, err)
+	}
+	b, err := parseTimeOfDay(begin, loc)
+	if err != nil {
+		return "", "", err
+	}
+	e, err := parseTimeOfDay(end, loc)
+	if err != nil {
+		return "", "", err
+	}
+
+	duration := e.Sub(b)
+	crossesMidnight := duration < 0
+	if crossesMidnight {
+		duration += 24 * time.Hour
+	}
+	switch {
+	case duration < MaintenanceTimeWindowMinimum:
+		return "", "", fmt.Errorf("maintenance window from %s to %s is too short, it must be at least %s", begin, end, HumanizeDuration(MaintenanceTimeWindowMinimum))
+	case duration > MaintenanceTimeWindowMaximum && crossesMidnight:
+		return "", "", fmt.Errorf("maintenance window ends at %s before it begins at %s", end, begin)
+	case duration > MaintenanceTimeWindowMaximum:
+		return "", "", fmt.Errorf("maintenance window from %s to %s is too long, it must be at most %s", begin, end, HumanizeDuration(MaintenanceTimeWindowMaximum))
+	}
+
+	return b.Format(maintenanceTimeFormat), e.Format(maintenanceTimeFormat), nil
+}
+
+// MaintenanceTimeIn returns the given begin or end of a maintenance time window of the api as today's time in the given location
+func MaintenanceTimeIn(t string, loc *time.Location) (time.Time, error) {
+	parsed, err := time.Parse(maintenanceTimeFormat, t)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid maintenance time:%s %w", t, err)
+	}
+	now := time.Now().In(parsed.Location())
+	today := time.Date(now.Year(), now.Month(), now.Day(), parsed.Hour(), parsed.Minute(), parsed.Second(), 0, parsed.Location())
+	return today.In(loc), nil
+}
+
+// parseTimeOfDay parses a time of day in the form 15:04 or 15:04:05 as today's time in the given location
+func parseTimeOfDay(s string, loc *time.Location) (time.Time, error) {
+	var t time.Time
+	var err error
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		t, err = time.Parse(layout, s)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time of day:%s, must be in the form hh:mm", s)
+	}
+	now := time.Now().In(loc)
+	return time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc), nil
+}
@@ -0,0 +1,56 @@
+package helper
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// PrefixWriter writes every line with the given prefix to the underlying writer,
+// writers sharing the same mutex can be used concurrently without mixing up their lines.
+type PrefixWriter struct {
+	out    io.Writer
+	prefix []byte
+	mu     *sync.Mutex
+	buf    bytes.Buffer
+}
+
+// NewPrefixWriter returns a writer prefixing each line written to out
+func NewPrefixWriter(out io.Writer, prefix string, mu *sync.Mutex) *PrefixWriter {
+	return &PrefixWriter{
+		out:    out,
+		prefix: []byte(prefix),
+		mu:     mu,
+	}
+}
+
+// Write writes all complete lines of p, an incomplete last line is kept until it is completed or Flush is called
+func (w *PrefixWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+	for {
+		i := bytes.IndexByte(w.buf.Bytes(), '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		err := w.writeLine(w.buf.Next(i + 1))
+		if err != nil {
+			return len(p), err
+		}
+	}
+}
+
+// Flush writes an incomplete last line
+func (w *PrefixWriter) Flush() error {
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	line := append(w.buf.Next(w.buf.Len()), '\n')
+	return w.writeLine(line)
+}
+
+func (w *PrefixWriter) writeLine(line []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err := w.out.Write(append(append([]byte{}, w.prefix...), line...))
+	return err
+}
@@ -0,0 +1,181 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"golang.org/x/term"
+)
+
+const sshDialTimeout = 10 * time.Second
+
+// SSHClientConfig returns the configuration to authenticate as user with the given private key,
+// host keys are pinned in the given known hosts file.
+func SSHClientConfig(user string, privateKey []byte, knownHostsFile string) (*ssh.ClientConfig, error) {
+	signer, err := ssh.ParsePrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse private key:%w", err)
+	}
+	return &ssh.ClientConfig{
+		User:            user,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: PinnedHostKeyCallback(knownHostsFile),
+		Timeout:         sshDialTimeout,
+	}, nil
+}
+
+// PinnedHostKeyCallback accepts the host key of a host seen for the first time and stores it in the given known hosts file,
+// afterwards only the stored key is accepted for this host.
+func PinnedHostKeyCallback(knownHostsFile string) ssh.HostKeyCallback {
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := os.MkdirAll(filepath.Dir(knownHostsFile), 0700)
+		if err != nil {
+			return err
+		}
+		f, err := os.OpenFile(knownHostsFile, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		callback, err := knownhosts.New(knownHostsFile)
+		if err != nil {
+			return err
+		}
+		err = callback(hostname, remote, key)
+		var keyErr *knownhosts.KeyError
+		if err == nil || !errors.As(err, &keyErr) {
+			return err
+		}
+		if len(keyErr.Want) > 0 {
+			return fmt.Errorf("host key of %s does not match the key stored in %s line %d, remove this line if the machine was replaced:%w", hostname, knownHostsFile, keyErr.Want[0].Line, err)
+		}
+
+		_, err = fmt.Fprintln(f, knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key))
+		return err
+	}
+}
+
+// SSHDial connects to the given address, if jumpHost is not nil the connection is established through it
+func SSHDial(address string, config *ssh.ClientConfig, jumpHost *ssh.Client) (*ssh.Client, error) {
+	if jumpHost == nil {
+		return ssh.Dial("tcp", address, config)
+	}
+	conn, err := jumpHost.Dial("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to %s through jump host:%w", address, err)
+	}
+	c, chans, reqs, err := ssh.NewClientConn(conn, address, config)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return ssh.NewClient(c, chans, reqs), nil
+}
+
+// SSHRun executes the command on the remote host, a non-zero exit status is returned as *ssh.ExitError
+func SSHRun(client *ssh.Client, command string, stdin io.Reader, stdout, stderr io.Writer) error {
+	session, err := client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	session.Stdin = stdin
+	session.Stdout = stdout
+	session.Stderr = stderr
+	return session.Run(command)
+}
+
+// SSHShell starts an interactive shell on the remote host, with a pty if stdin is a terminal
+func SSHShell(client *ssh.Client) error {
+	session, err := client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	session.Stdin = os.Stdin
+	session.Stdout = os.Stdout
+	session.Stderr = os.Stderr
+
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		width, height, err := term.GetSize(fd)
+		if err != nil {
+			width, height = 80, 24
+		}
+		termType := os.Getenv("TERM")
+		if termType == "" {
+			termType = "xterm-256color"
+		}
+		err = session.RequestPty(termType, height, width, ssh.TerminalModes{ssh.ECHO: 1})
+		if err != nil {
+			return fmt.Errorf("unable to request pty:%w", err)
+		}
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = term.Restore(fd, state)
+		}()
+		stop := watchTerminalSize(fd, session)
+		defer stop()
+	}
+
+	err = session.Shell()
+	if err != nil {
+		return err
+	}
+	return session.Wait()
+}
+
+// SSHForward tunnels every connection accepted by the listener to the remote address through the ssh connection,
+// it returns when the listener is closed or the ssh connection is lost.
+func SSHForward(client *ssh.Client, listener net.Listener, remoteAddress string, errOut io.Writer) error {
+	lost := make(chan error, 1)
+	go func() {
+		lost <- client.Wait()
+		listener.Close()
+	}()
+
+	for {
+		local, err := listener.Accept()
+		if err != nil {
+			select {
+			case err := <-lost:
+				return fmt.Errorf("ssh connection lost:%w", err)
+			default:
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go func() {
+			defer local.Close()
+			remote, err := client.Dial("tcp", remoteAddress)
+			if err != nil {
+				fmt.Fprintf(errOut, "unable to connect to %s:%v\n", remoteAddress, err)
+				return
+			}
+			defer remote.Close()
+			done := make(chan struct{}, 2)
+			go func() {
+				_, _ = io.Copy(remote, local)
+				done <- struct{}{}
+			}()
+			go func() {
+				_, _ = io.Copy(local, remote)
+				done <- struct{}{}
+			}()
+			<-done
+		}()
+	}
+}
@@ -0,0 +1,37 @@
+//go:build !windows
+// +build !windows
+
+package helper
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/term"
+)
+
+// watchTerminalSize forwards size changes of the local terminal to the session until the returned function is called
+func watchTerminalSize(fd int, session *ssh.Session) func() {
+	sigs := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sigs, syscall.SIGWINCH)
+	go func() {
+		for {
+			select {
+			case <-sigs:
+				width, height, err := term.GetSize(fd)
+				if err == nil {
+					_ = session.WindowChange(height, width)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(sigs)
+		close(done)
+	}
+}
@@ -0,0 +1,10 @@
+package helper
+
+import (
+	"golang.org/x/crypto/ssh"
+)
+
+// watchTerminalSize does nothing on windows as there is no signal for size changes of the console
+func watchTerminalSize(fd int, session *ssh.Session) func() {
+	return func() {}
+}
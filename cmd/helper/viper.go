@@ -1,6 +1,11 @@
 package helper
 
-import "github.com/spf13/viper"
+import (
+	"encoding/csv"
+	"strings"
+
+	"github.com/spf13/viper"
+)
 
 // AtLeastOneViperStringFlagGiven ensure at least one string flag is given
 func AtLeastOneViperStringFlagGiven(flags ...string) bool {
@@ -60,6 +65,26 @@ func ViperStringSlice(flag string) []string {
 	return value
 }
 
+// ViperStringArray returns the string slice for the given string array flag.
+// Other than string slice flags, the values of a string array flag are not split at commas,
+// viper however only knows their csv encoded string representation.
+func ViperStringArray(flag string) []string {
+	switch v := viper.Get(flag).(type) {
+	case string:
+		v = strings.TrimSuffix(strings.TrimPrefix(v, "["), "]")
+		if v == "" {
+			return nil
+		}
+		values, err := csv.NewReader(strings.NewReader(v)).Read()
+		if err != nil {
+			return nil
+		}
+		return values
+	default:
+		return ViperStringSlice(flag)
+	}
+}
+
 // ViperBool returns the bool pointer for the given flag
 func ViperBool(flag string) *bool {
 	if !viper.GetBool(flag) {
@@ -0,0 +1,225 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/fi-ts/cloud-go/api/models"
+	"github.com/go-openapi/strfmt"
+)
+
+type (
+	// ClusterChange is the before and after value of a field changed by a cluster update
+	ClusterChange struct {
+		Field  string `json:"field"`
+		Before string `json:"before"`
+		After  string `json:"after"`
+		// RollsWorkers is true if the change replaces the worker nodes of a worker group
+		RollsWorkers bool `json:"rolls_workers"`
+	}
+	// ClusterChanges are all changes of a cluster update
+	ClusterChanges []ClusterChange
+
+	// ClusterChangesTablePrinter print the changes of a cluster update in a Table
+	ClusterChangesTablePrinter struct {
+		TablePrinter
+	}
+)
+
+// NewClusterChanges returns the changes the given update request makes to the given cluster,
+// fields which are not set in the request stay untouched by the update and are not compared.
+func NewClusterChanges(current *models.V1ClusterResponse, request *models.V1ClusterUpdateRequest) ClusterChanges {
+	var changes ClusterChanges
+	add := func(field, before, after string, rollsWorkers bool) {
+		if before == after {
+			return
+		}
+		changes = append(changes, ClusterChange{Field: field, Before: before, After: after, RollsWorkers: rollsWorkers})
+	}
+
+	if request.Kubernetes != nil {
+		k8s := current.Kubernetes
+		if k8s == nil {
+			k8s = &models.V1Kubernetes{}
+		}
+		if request.Kubernetes.Version != nil {
+			add("Kubernetes Version", StrValue(k8s.Version), StrValue(request.Kubernetes.Version), false)
+		}
+		if request.Kubernetes.AllowPrivilegedContainers != nil {
+			add("Allow Privileged Containers", boolString(k8s.AllowPrivilegedContainers), boolString(request.Kubernetes.AllowPrivilegedContainers), false)
+		}
+		if request.Kubernetes.ExpirationDate != nil {
+			add("Expiration", dateTimeString(k8s.ExpirationDate), dateTimeString(request.Kubernetes.ExpirationDate), false)
+		}
+	}
+
+	if request.Workers != nil {
+		currentWorkers := map[string]*models.V1Worker{}
+		for _, w := range current.Workers {
+			currentWorkers[StrValue(w.Name)] = w
+		}
+		requestedWorkers := map[string]bool{}
+		for _, w := range request.Workers {
+			name := StrValue(w.Name)
+			requestedWorkers[name] = true
+			c, ok := currentWorkers[name]
+			if !ok {
+				add("Worker "+name, "", workerSummary(w), false)
+				continue
+			}
+			prefix := "Worker " + name + " "
+			add(prefix+"Machine Type", StrValue(c.MachineType), StrValue(w.MachineType), true)
+			add(prefix+"Image", machineImageString(c.MachineImage), machineImageString(w.MachineImage), true)
+			add(prefix+"CRI", StrValue(c.CRI), StrValue(w.CRI), true)
+			add(prefix+"Minimum", int32String(c.Minimum), int32String(w.Minimum), false)
+			add(prefix+"Maximum", int32String(c.Maximum), int32String(w.Maximum), false)
+			add(prefix+"Max Surge", StrValue(c.MaxSurge), StrValue(w.MaxSurge), false)
+			add(prefix+"Max Unavailable", StrValue(c.MaxUnavailable), StrValue(w.MaxUnavailable), false)
+			add(prefix+"Health Timeout", durationString(c.HealthTimeout), durationString(w.HealthTimeout), false)
+			add(prefix+"Drain Timeout", durationString(c.DrainTimeout), durationString(w.DrainTimeout), false)
+		}
+		for _, c := range current.Workers {
+			if !requestedWorkers[StrValue(c.Name)] {
+				add("Worker "+StrValue(c.Name), workerSummary(c), "", false)
+			}
+		}
+	}
+
+	if request.FirewallSize != nil {
+		add("Firewall Type", StrValue(current.FirewallSize), StrValue(request.FirewallSize), false)
+	}
+	if request.FirewallImage != nil {
+		add("Firewall Image", StrValue(current.FirewallImage), StrValue(request.FirewallImage), false)
+	}
+	if request.FirewallControllerVersion != nil {
+		add("Firewall Controller", StrValue(current.FirewallControllerVersion), StrValue(request.FirewallControllerVersion), false)
+	}
+	if request.AdditionalNetworks != nil {
+		add("External Networks", sortedJoin(current.AdditionalNetworks), sortedJoin(request.AdditionalNetworks), false)
+	}
+	if request.EgressRules != nil {
+		add("Egress", egressString(current.EgressRules), egressString(request.EgressRules), false)
+	}
+	if request.Labels != nil {
+		add("Labels", labelsString(current.Labels), labelsString(request.Labels), false)
+	}
+	if request.Purpose != nil {
+		add("Purpose", StrValue(current.Purpose), StrValue(request.Purpose), false)
+	}
+
+	if request.Maintenance != nil {
+		maintenance := current.Maintenance
+		if maintenance == nil {
+			maintenance = &models.V1Maintenance{}
+		}
+		if a := request.Maintenance.AutoUpdate; a != nil {
+			currentAutoUpdate := maintenance.AutoUpdate
+			if currentAutoUpdate == nil {
+				currentAutoUpdate = &models.V1MaintenanceAutoUpdate{}
+			}
+			add("Auto Update Kubernetes", boolString(currentAutoUpdate.KubernetesVersion), boolString(a.KubernetesVersion), false)
+			add("Auto Update Machine Images", boolString(currentAutoUpdate.MachineImage), boolString(a.MachineImage), false)
+		}
+		if w := request.Maintenance.TimeWindow; w != nil {
+			add("Maintenance Window", timeWindowString(maintenance.TimeWindow), timeWindowString(w), false)
+		}
+	}
+
+	return changes
+}
+
+// RollWorkers returns true if one of the changes replaces worker nodes
+func (c ClusterChanges) RollWorkers() bool {
+	for _, change := range c {
+		if change.RollsWorkers {
+			return true
+		}
+	}
+	return false
+}
+
+// Print the changes of a cluster update, changes replacing worker nodes are highlighted
+func (s ClusterChangesTablePrinter) Print(data ClusterChanges) {
+	s.shortHeader = []string{"Field", "Before", "After"}
+	s.wideHeader = s.shortHeader
+	for _, c := range data {
+		row := []string{c.Field, c.Before, c.After}
+		if c.RollsWorkers {
+			row = []string{color.YellowString(c.Field + " *"), color.YellowString(c.Before), color.YellowString(c.After)}
+		}
+		s.addShortData(row, c)
+		s.addWideData(row, c)
+	}
+	s.render()
+}
+
+func workerSummary(w *models.V1Worker) string {
+	return fmt.Sprintf("%s %s %s-%s", StrValue(w.MachineType), machineImageString(w.MachineImage), int32String(w.Minimum), int32String(w.Maximum))
+}
+
+func machineImageString(image *models.V1MachineImage) string {
+	if image == nil {
+		return ""
+	}
+	return StrValue(image.Name) + "-" + StrValue(image.Version)
+}
+
+func boolString(b *bool) string {
+	if b == nil {
+		return ""
+	}
+	return fmt.Sprintf("%t", *b)
+}
+
+func dateTimeString(d *strfmt.DateTime) string {
+	if d == nil {
+		return ""
+	}
+	return d.String()
+}
+
+func int32String(i *int32) string {
+	if i == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d", *i)
+}
+
+func durationString(d int64) string {
+	if d == 0 {
+		return ""
+	}
+	return time.Duration(d).String()
+}
+
+func sortedJoin(s []string) string {
+	sorted := append([]string{}, s...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+func egressString(rules []*models.V1EgressRule) string {
+	var egress []string
+	for _, r := range rules {
+		egress = append(egress, StrValue(r.NetworkID)+":"+sortedJoin(r.IPs))
+	}
+	return sortedJoin(egress)
+}
+
+func labelsString(labels map[string]string) string {
+	var l []string
+	for k, v := range labels {
+		l = append(l, k+"="+v)
+	}
+	return sortedJoin(l)
+}
+
+func timeWindowString(w *models.V1MaintenanceTimeWindow) string {
+	if w == nil {
+		return ""
+	}
+	return StrValue(w.Begin) + " - " + StrValue(w.End)
+}
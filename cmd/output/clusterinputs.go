@@ -0,0 +1,117 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Masterminds/semver"
+	"github.com/fi-ts/cloud-go/api/models"
+)
+
+// the kinds of cluster inputs
+const (
+	ClusterInputVersions       = "versions"
+	ClusterInputMachineTypes   = "machinetypes"
+	ClusterInputImages         = "images"
+	ClusterInputFirewallTypes  = "firewalltypes"
+	ClusterInputFirewallImages = "firewallimages"
+	ClusterInputNetworks       = "networks"
+	ClusterInputPartitions     = "partitions"
+)
+
+// ClusterInputKinds are all kinds of cluster inputs in the order they are printed
+var ClusterInputKinds = []string{
+	ClusterInputVersions,
+	ClusterInputMachineTypes,
+	ClusterInputImages,
+	ClusterInputFirewallTypes,
+	ClusterInputFirewallImages,
+	ClusterInputNetworks,
+	ClusterInputPartitions,
+}
+
+type (
+	// ClusterInput is a possible value of a cluster input
+	ClusterInput struct {
+		Kind  string
+		Value string
+		// Flag is the flag of cluster create which takes the value
+		Flag string
+	}
+	// ClusterInputs are the possible values of the cluster inputs
+	ClusterInputs []ClusterInput
+
+	// ClusterInputsTablePrinter print the possible values of the cluster inputs in a Table
+	ClusterInputsTablePrinter struct {
+		TablePrinter
+	}
+)
+
+// NewClusterInputs returns the values of the given constraints of the given kinds, all kinds if none are given
+func NewClusterInputs(sc *models.V1ShootConstraints, kinds ...string) (ClusterInputs, error) {
+	if len(kinds) == 0 {
+		kinds = ClusterInputKinds
+	}
+	var inputs ClusterInputs
+	add := func(kind, flag string, values []string) {
+		for _, v := range values {
+			inputs = append(inputs, ClusterInput{Kind: kind, Value: v, Flag: flag})
+		}
+	}
+	for _, kind := range kinds {
+		switch kind {
+		case ClusterInputVersions:
+			add(kind, "--version", sortedVersions(sc.KubernetesVersions))
+		case ClusterInputMachineTypes:
+			add(kind, "--machinetype", sortedStrings(sc.MachineTypes))
+		case ClusterInputImages:
+			var images []string
+			for _, image := range sc.MachineImages {
+				images = append(images, machineImageString(image))
+			}
+			add(kind, "--machineimage", sortedStrings(images))
+		case ClusterInputFirewallTypes:
+			add(kind, "--firewalltype", sortedStrings(sc.FirewallTypes))
+		case ClusterInputFirewallImages:
+			add(kind, "--firewallimage", sortedStrings(sc.FirewallImages))
+		case ClusterInputNetworks:
+			add(kind, "--external-networks", sortedStrings(sc.Networks))
+		case ClusterInputPartitions:
+			add(kind, "--partition", sortedStrings(sc.Partitions))
+		default:
+			return nil, fmt.Errorf("unknown kind of cluster input:%s, must be one of %v", kind, ClusterInputKinds)
+		}
+	}
+	return inputs, nil
+}
+
+// Print the possible values of the cluster inputs
+func (s ClusterInputsTablePrinter) Print(data ClusterInputs) {
+	s.shortHeader = []string{"Kind", "Value"}
+	s.wideHeader = []string{"Kind", "Value", "Flag"}
+	for _, i := range data {
+		s.addShortData([]string{i.Kind, i.Value}, i)
+		s.addWideData([]string{i.Kind, i.Value, i.Flag}, i)
+	}
+	s.render()
+}
+
+// sortedVersions sorts semantic versions with the latest first, invalid versions are put last
+func sortedVersions(versions []string) []string {
+	sorted := append([]string{}, versions...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, errA := semver.NewVersion(sorted[i])
+		b, errB := semver.NewVersion(sorted[j])
+		if errA != nil || errB != nil {
+			return errB != nil && errA == nil
+		}
+		return a.GreaterThan(b)
+	})
+	return sorted
+}
+
+func sortedStrings(s []string) []string {
+	sorted := append([]string{}, s...)
+	sort.Strings(sorted)
+	return sorted
+}
@@ -0,0 +1,57 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fi-ts/cloud-go/api/models"
+)
+
+type (
+	// ClusterTemplate is a named partial cluster create request of the configuration
+	ClusterTemplate struct {
+		Name string `json:"name"`
+		// Source is global for templates which can be used in all contexts, otherwise the name of the context
+		Source  string                         `json:"source"`
+		Request *models.V1ClusterCreateRequest `json:"request"`
+	}
+	// ClusterTemplates are the cluster templates of the configuration
+	ClusterTemplates []ClusterTemplate
+
+	// ClusterTemplateTablePrinter print the cluster templates in a Table
+	ClusterTemplateTablePrinter struct {
+		TablePrinter
+	}
+)
+
+// Print the cluster templates with the values they provide
+func (s ClusterTemplateTablePrinter) Print(data ClusterTemplates) {
+	s.shortHeader = []string{"Name", "Source", "Partition", "Purpose", "Version", "Firewall", "Workers"}
+	s.wideHeader = []string{"Name", "Source", "Project", "Partition", "Purpose", "Version", "Firewall", "Networks", "Workers"}
+	for _, t := range data {
+		r := t.Request
+		version := ""
+		if r.Kubernetes != nil {
+			version = StrValue(r.Kubernetes.Version)
+		}
+		var workers []string
+		for _, w := range r.Workers {
+			worker := strings.TrimSpace(fmt.Sprintf("%s %s", StrValue(w.MachineType), templateWorkerSize(w)))
+			if w.Name != nil {
+				worker = *w.Name + ": " + worker
+			}
+			workers = append(workers, worker)
+		}
+		s.addShortData([]string{t.Name, t.Source, StrValue(r.PartitionID), StrValue(r.Purpose), version, StrValue(r.FirewallSize), strings.Join(workers, "\n")}, t)
+		s.addWideData([]string{t.Name, t.Source, StrValue(r.ProjectID), StrValue(r.PartitionID), StrValue(r.Purpose), version, StrValue(r.FirewallSize),
+			strings.Join(r.AdditionalNetworks, "\n"), strings.Join(workers, "\n")}, t)
+	}
+	s.render()
+}
+
+func templateWorkerSize(w *models.V1Worker) string {
+	if w.Minimum == nil && w.Maximum == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s-%s", int32String(w.Minimum), int32String(w.Maximum))
+}
@@ -0,0 +1,185 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fi-ts/cloud-go/api/models"
+	"github.com/fi-ts/cloudctl/cmd/helper"
+)
+
+// describeWriter writes indented "key: value" lines aligned in columns
+type describeWriter struct {
+	tw *tabwriter.Writer
+}
+
+func (d describeWriter) section(title string) {
+	fmt.Fprintf(d.tw, "%s:\n", title)
+}
+
+func (d describeWriter) line(indent int, key string, value interface{}) {
+	fmt.Fprintf(d.tw, "%s%s:\t%v\n", strings.Repeat("  ", indent), key, value)
+}
+
+func (d describeWriter) text(indent int, format string, a ...interface{}) {
+	fmt.Fprintf(d.tw, "%s%s\n", strings.Repeat("  ", indent), fmt.Sprintf(format, a...))
+}
+
+// DescribeShoot writes a sectioned human readable description of the cluster
+func DescribeShoot(w io.Writer, shoot *models.V1ClusterResponse) error {
+	d := describeWriter{tw: tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)}
+
+	d.line(0, "Name", StrValue(shoot.Name))
+	d.line(0, "UID", StrValue(shoot.ID))
+	d.line(0, "Tenant", StrValue(shoot.Tenant))
+	d.line(0, "Project", StrValue(shoot.ProjectID))
+	d.line(0, "Description", StrValue(shoot.Description))
+	d.line(0, "Purpose", StrValue(shoot.Purpose))
+	d.line(0, "Partition", StrValue(shoot.PartitionID))
+	d.line(0, "DNS Endpoint", StrValue(shoot.DNSEndpoint))
+	if shoot.CreationTimestamp != nil {
+		created := time.Time(*shoot.CreationTimestamp)
+		d.line(0, "Created", fmt.Sprintf("%s (%s ago)", created.Format(time.RFC3339), helper.HumanizeDuration(time.Since(created))))
+	}
+	d.line(0, "Labels", describeLabels(shoot.Labels))
+
+	d.section("Kubernetes")
+	if k := shoot.Kubernetes; k != nil {
+		d.line(1, "Version", StrValue(k.Version))
+		if k.ExpirationDate != nil && !time.Time(*k.ExpirationDate).IsZero() {
+			d.line(1, "Supported Until", time.Time(*k.ExpirationDate).Format("2006-01-02"))
+		}
+		d.line(1, "Allow Privileged", boolString(k.AllowPrivilegedContainers))
+	}
+	if n := shoot.Networking; n != nil {
+		d.line(1, "Networking", StrValue(n.Type))
+		d.line(2, "Pods", StrValue(n.Pods))
+		d.line(2, "Services", StrValue(n.Services))
+		d.line(2, "Nodes", StrValue(n.Nodes))
+	}
+	if len(shoot.ControlPlaneFeatureGates) > 0 {
+		d.line(1, "Feature Gates", strings.Join(shoot.ControlPlaneFeatureGates, ", "))
+	}
+
+	d.section("Workers")
+	for _, wg := range shoot.Workers {
+		d.text(1, "%s:", StrValue(wg.Name))
+		d.line(2, "Machine Type", StrValue(wg.MachineType))
+		d.line(2, "Image", machineImageString(wg.MachineImage))
+		d.line(2, "Autoscaler", fmt.Sprintf("min %s, max %s", int32String(wg.Minimum), int32String(wg.Maximum)))
+		d.line(2, "Max Surge", StrValue(wg.MaxSurge))
+		d.line(2, "Max Unavailable", StrValue(wg.MaxUnavailable))
+		cri := StrValue(wg.CRI)
+		if cri == "" {
+			cri = "docker"
+		}
+		d.line(2, "Container Runtime", cri)
+		if wg.HealthTimeout != 0 {
+			d.line(2, "Health Timeout", durationString(wg.HealthTimeout))
+		}
+		if wg.DrainTimeout != 0 {
+			d.line(2, "Drain Timeout", durationString(wg.DrainTimeout))
+		}
+	}
+
+	d.section("Firewall")
+	d.line(1, "Type", StrValue(shoot.FirewallSize))
+	d.line(1, "Image", StrValue(shoot.FirewallImage))
+	d.line(1, "Controller", StrValue(shoot.FirewallControllerVersion))
+	d.line(1, "Networks", strings.Join(shoot.AdditionalNetworks, ", "))
+	if len(shoot.EgressRules) > 0 {
+		d.text(1, "Egress:")
+		for _, r := range shoot.EgressRules {
+			d.line(2, StrValue(r.NetworkID), strings.Join(r.IPs, ", "))
+		}
+	}
+
+	d.section("Maintenance")
+	if m := shoot.Maintenance; m != nil {
+		if tw := m.TimeWindow; tw != nil && tw.Begin != nil && tw.End != nil {
+			window := timeWindowString(tw)
+			begin, errBegin := helper.MaintenanceTimeIn(*tw.Begin, time.Local)
+			end, errEnd := helper.MaintenanceTimeIn(*tw.End, time.Local)
+			if errBegin == nil && errEnd == nil {
+				window = fmt.Sprintf("%s - %s (local time)", begin.Format("15:04"), end.Format("15:04 MST"))
+			}
+			d.line(1, "Time Window", window)
+		}
+		if a := m.AutoUpdate; a != nil {
+			d.line(1, "Auto Update Kubernetes", boolString(a.KubernetesVersion))
+			d.line(1, "Auto Update Machine Images", boolString(a.MachineImage))
+		}
+	}
+
+	if s := shoot.Status; s != nil {
+		d.section("Conditions")
+		for _, c := range s.Conditions {
+			d.line(1, StrValue(c.Type), fmt.Sprintf("%s\t%s\t%s", StrValue(c.Status), StrValue(c.LastTransitionTime), StrValue(c.Message)))
+		}
+		if op := s.LastOperation; op != nil {
+			d.section("Last Operation")
+			d.line(1, "Type", StrValue(op.Type))
+			d.line(1, "State", fmt.Sprintf("%s %s%%", StrValue(op.State), int32String(op.Progress)))
+			d.line(1, "Updated", StrValue(op.LastUpdateTime))
+			d.line(1, "Description", StrValue(op.Description))
+		}
+		if len(s.LastErrors) > 0 {
+			d.section("Last Errors")
+			for _, e := range s.LastErrors {
+				d.line(1, e.TaskID, fmt.Sprintf("%s\t%s", e.LastUpdateTime, StrValue(e.Description)))
+			}
+		}
+	}
+
+	d.section("Machines")
+	machines := append(append([]*models.ModelsV1MachineResponse{}, shoot.Firewalls...), shoot.Machines...)
+	for _, m := range machines {
+		name, image, expires := "", "", ""
+		if a := m.Allocation; a != nil {
+			name = StrValue(a.Name)
+			if a.Image != nil {
+				image = StrValue(a.Image.ID)
+				if a.Image.ExpirationDate != nil {
+					if t, err := time.Parse(time.RFC3339, *a.Image.ExpirationDate); err == nil && !t.IsZero() {
+						expires = "expires " + t.Format("2006-01-02")
+						if time.Now().After(t) {
+							expires = "expired " + t.Format("2006-01-02")
+						}
+					}
+				}
+			}
+		}
+		size := ""
+		if m.Size != nil {
+			size = StrValue(m.Size.ID)
+		}
+		d.line(1, name, fmt.Sprintf("%s\t%s\t%s\t%s", StrValue(m.ID), size, image, expires))
+	}
+
+	issues := ShootIssues(shoot)
+	if len(issues) > 0 {
+		d.section("Issues")
+		sort.SliceStable(issues, func(i, j int) bool { return issues[i].Severity > issues[j].Severity })
+		for _, issue := range issues {
+			d.line(1, issue.Severity.String(), issue.Message)
+		}
+	}
+
+	return d.tw.Flush()
+}
+
+func describeLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return "<none>"
+	}
+	var l []string
+	for k, v := range labels {
+		l = append(l, k+"="+v)
+	}
+	sort.Strings(l)
+	return strings.Join(l, ", ")
+}
@@ -0,0 +1,93 @@
+package output
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+// HoursPerMonth is the average number of hours of a month
+const HoursPerMonth = 24 * 365 / 12.0
+
+type (
+	// MachineResources are the resources of a machine type which are billed
+	MachineResources struct {
+		CPU       float64 `json:"cpu" yaml:"cpu"`
+		MemoryGi  float64 `json:"memory-gi" yaml:"memory-gi" mapstructure:"memory-gi"`
+		StorageGi float64 `json:"storage-gi" yaml:"storage-gi" mapstructure:"storage-gi"`
+	}
+
+	// ClusterCostEstimateItem are the estimated costs of the machines of a worker group or of the firewall
+	ClusterCostEstimateItem struct {
+		Name        string           `json:"name"`
+		MachineType string           `json:"machine_type"`
+		Resources   MachineResources `json:"resources"`
+		Minimum     int32            `json:"minimum"`
+		Maximum     int32            `json:"maximum"`
+		// MachineMonth are the costs of a single machine per month
+		MachineMonth float64 `json:"machine_month"`
+		MinMonth     float64 `json:"min_month"`
+		MaxMonth     float64 `json:"max_month"`
+	}
+
+	// ClusterCostEstimate are the estimated monthly costs of a cluster, depending on the autoscaling of the worker groups
+	ClusterCostEstimate struct {
+		Items    []ClusterCostEstimateItem `json:"items"`
+		MinMonth float64                   `json:"min_month"`
+		MaxMonth float64                   `json:"max_month"`
+	}
+
+	// ClusterCostEstimateTablePrinter print the estimated costs of a cluster in a Table
+	ClusterCostEstimateTablePrinter struct {
+		TablePrinter
+	}
+)
+
+// MachineMonthCosts returns the costs of a machine with the given resources per month
+// with the prices costs-cpu-hour, costs-memory-gi-hour and costs-storage-gi-hour of the configuration
+func MachineMonthCosts(r MachineResources) float64 {
+	hourly := r.CPU*viper.GetFloat64("costs-cpu-hour") +
+		r.MemoryGi*viper.GetFloat64("costs-memory-gi-hour") +
+		r.StorageGi*viper.GetFloat64("costs-storage-gi-hour")
+	return hourly * HoursPerMonth
+}
+
+// Add adds machines with the given resources which are scaled between minimum and maximum to the estimate
+func (e *ClusterCostEstimate) Add(name, machineType string, r MachineResources, minimum, maximum int32) {
+	machine := MachineMonthCosts(r)
+	item := ClusterCostEstimateItem{
+		Name:         name,
+		MachineType:  machineType,
+		Resources:    r,
+		Minimum:      minimum,
+		Maximum:      maximum,
+		MachineMonth: machine,
+		MinMonth:     machine * float64(minimum),
+		MaxMonth:     machine * float64(maximum),
+	}
+	e.Items = append(e.Items, item)
+	e.MinMonth += item.MinMonth
+	e.MaxMonth += item.MaxMonth
+}
+
+// Print the estimated costs of a cluster with a row per worker group and the firewall and the total range
+func (s ClusterCostEstimateTablePrinter) Print(data *ClusterCostEstimate) {
+	s.shortHeader = []string{"Name", "Machine Type", "Machines", "Per Machine", "Min / Month", "Max / Month"}
+	s.wideHeader = []string{"Name", "Machine Type", "CPU", "Memory", "Storage", "Machines", "Per Machine", "Min / Month", "Max / Month"}
+	for _, i := range data.Items {
+		machines := fmt.Sprintf("%d", i.Minimum)
+		if i.Minimum != i.Maximum {
+			machines = fmt.Sprintf("%d-%d", i.Minimum, i.Maximum)
+		}
+		s.addShortData([]string{i.Name, i.MachineType, machines, euro(i.MachineMonth), euro(i.MinMonth), euro(i.MaxMonth)}, i)
+		s.addWideData([]string{i.Name, i.MachineType, fmt.Sprintf("%g", i.Resources.CPU), fmt.Sprintf("%gGi", i.Resources.MemoryGi), fmt.Sprintf("%gGi", i.Resources.StorageGi),
+			machines, euro(i.MachineMonth), euro(i.MinMonth), euro(i.MaxMonth)}, i)
+	}
+	s.addShortData([]string{"Total", "", "", "", euro(data.MinMonth), euro(data.MaxMonth)}, data)
+	s.addWideData([]string{"Total", "", "", "", "", "", "", euro(data.MinMonth), euro(data.MaxMonth)}, data)
+	s.render()
+}
+
+func euro(amount float64) string {
+	return fmt.Sprintf("%.2f €", amount)
+}
@@ -0,0 +1,139 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fi-ts/cloud-go/api/models"
+)
+
+const (
+	// ClusterEventCondition is the event of a changed condition of a cluster
+	ClusterEventCondition = "condition"
+	// ClusterEventOperation is the event of a progressing or changed last operation of a cluster
+	ClusterEventOperation = "operation"
+	// ClusterEventError is the event of a new last error of a cluster
+	ClusterEventError = "error"
+	// ClusterEventAdded is the event of a cluster which appeared
+	ClusterEventAdded = "added"
+	// ClusterEventChanged is the event of a changed cluster
+	ClusterEventChanged = "changed"
+	// ClusterEventDeleted is the event of a cluster which disappeared
+	ClusterEventDeleted = "deleted"
+)
+
+// ClusterEvent is a change of a cluster observed while watching it
+type ClusterEvent struct {
+	Time      time.Time                 `json:"time"`
+	ClusterID string                    `json:"cluster_id"`
+	Type      string                    `json:"type"`
+	Message   string                    `json:"message,omitempty"`
+	Cluster   *models.V1ClusterResponse `json:"cluster,omitempty"`
+}
+
+// String returns the event as single log line
+func (e ClusterEvent) String() string {
+	return fmt.Sprintf("%s  %s  %-9s  %s", e.Time.Format("2006-01-02 15:04:05"), e.ClusterID, e.Type, e.Message)
+}
+
+// ClusterLogEvents returns the changes of conditions, last operation and last errors between two states of a cluster,
+// if before is nil the whole state of after is returned as events.
+func ClusterLogEvents(before, after *models.V1ClusterResponse) []ClusterEvent {
+	now := time.Now()
+	id := StrValue(after.ID)
+	var events []ClusterEvent
+	add := func(eventType, message string) {
+		events = append(events, ClusterEvent{Time: now, ClusterID: id, Type: eventType, Message: message})
+	}
+
+	oldStatus := &models.V1beta1ShootStatus{}
+	if before != nil && before.Status != nil {
+		oldStatus = before.Status
+	}
+	newStatus := after.Status
+	if newStatus == nil {
+		return nil
+	}
+
+	oldConditions := map[string]*models.V1beta1Condition{}
+	for _, c := range oldStatus.Conditions {
+		oldConditions[StrValue(c.Type)] = c
+	}
+	for _, c := range newStatus.Conditions {
+		old, ok := oldConditions[StrValue(c.Type)]
+		switch {
+		case !ok:
+			add(ClusterEventCondition, fmt.Sprintf("%s is %s: %s", StrValue(c.Type), StrValue(c.Status), StrValue(c.Message)))
+		case StrValue(old.Status) != StrValue(c.Status):
+			add(ClusterEventCondition, fmt.Sprintf("%s changed from %s to %s: %s", StrValue(c.Type), StrValue(old.Status), StrValue(c.Status), StrValue(c.Message)))
+		}
+	}
+
+	if op := newStatus.LastOperation; op != nil {
+		old := oldStatus.LastOperation
+		if old == nil || StrValue(old.Type) != StrValue(op.Type) || StrValue(old.State) != StrValue(op.State) ||
+			int32String(old.Progress) != int32String(op.Progress) || StrValue(old.Description) != StrValue(op.Description) {
+			add(ClusterEventOperation, fmt.Sprintf("%s %s %s%%: %s", StrValue(op.Type), StrValue(op.State), int32String(op.Progress), StrValue(op.Description)))
+		}
+	}
+
+	oldErrors := map[string]bool{}
+	for _, e := range oldStatus.LastErrors {
+		oldErrors[e.TaskID+StrValue(e.Description)] = true
+	}
+	for _, e := range newStatus.LastErrors {
+		if oldErrors[e.TaskID+StrValue(e.Description)] {
+			continue
+		}
+		add(ClusterEventError, fmt.Sprintf("%s: %s", e.TaskID, StrValue(e.Description)))
+	}
+
+	return events
+}
+
+// ClusterListEvents returns the clusters which were added, changed or deleted between two listings
+func ClusterListEvents(before, after []*models.V1ClusterResponse) []ClusterEvent {
+	now := time.Now()
+	old := map[string]*models.V1ClusterResponse{}
+	for _, c := range before {
+		old[StrValue(c.ID)] = c
+	}
+
+	var events []ClusterEvent
+	seen := map[string]bool{}
+	for _, c := range after {
+		id := StrValue(c.ID)
+		seen[id] = true
+		o, ok := old[id]
+		switch {
+		case !ok:
+			events = append(events, ClusterEvent{Time: now, ClusterID: id, Type: ClusterEventAdded, Cluster: c})
+		case !sameCluster(o, c):
+			events = append(events, ClusterEvent{Time: now, ClusterID: id, Type: ClusterEventChanged, Cluster: c})
+		}
+	}
+	for _, c := range before {
+		id := StrValue(c.ID)
+		if !seen[id] {
+			events = append(events, ClusterEvent{Time: now, ClusterID: id, Type: ClusterEventDeleted, Cluster: c})
+		}
+	}
+	return events
+}
+
+// sameCluster returns true if both clusters are shown the same in the cluster tables, other fields like the
+// update times of the conditions change on every poll. The age is compared by the creation time as it changes all the time.
+func sameCluster(a, b *models.V1ClusterResponse) bool {
+	if dateTimeString(a.CreationTimestamp) != dateTimeString(b.CreationTimestamp) {
+		return false
+	}
+	return shootColumns(a) == shootColumns(b)
+}
+
+func shootColumns(shoot *models.V1ClusterResponse) string {
+	withoutAge := *shoot
+	withoutAge.CreationTimestamp = nil
+	short, wide, issues := shootData(&withoutAge, true)
+	return strings.Join(short, "\t") + "\n" + strings.Join(wide, "\t") + "\n" + strings.Join(issues, "\n")
+}
@@ -3,6 +3,7 @@ package output
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"math"
 	"net"
 	"sort"
@@ -74,6 +75,11 @@ func sortIPs(v1ips []*models.ModelsV1IPResponse) []*models.ModelsV1IPResponse {
 	return result
 }
 
+// StrValue returns the value of a string pointer of not nil, otherwise empty string
+func StrValue(strPtr *string) string {
+	return strValue(strPtr)
+}
+
 // strValue returns the value of a string pointer of not nil, otherwise empty string
 func strValue(strPtr *string) string {
 	if strPtr != nil {
@@ -89,12 +95,12 @@ func int64Value(i *int64) int64 {
 	return 0
 }
 
-func printStringSlice(s []string) {
+func printStringSlice(w io.Writer, s []string) {
 	var dashed []string
 	for _, elem := range s {
 		dashed = append(dashed, "- "+elem)
 	}
-	fmt.Println(strings.Join(dashed, "\n"))
+	fmt.Fprintln(w, strings.Join(dashed, "\n"))
 }
 
 func uniqueStringSlice(s []string) []string {
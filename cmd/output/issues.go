@@ -0,0 +1,178 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/fi-ts/cloud-go/api/models"
+)
+
+// IssueSeverity is the severity of a cluster issue, the values are the exit codes of a nagios plugin
+type IssueSeverity int
+
+const (
+	// IssueSeverityNone means there is no issue
+	IssueSeverityNone IssueSeverity = 0
+	// IssueSeverityWarning is an issue which requires action in the near future
+	IssueSeverityWarning IssueSeverity = 1
+	// IssueSeverityCritical is an issue which requires action now
+	IssueSeverityCritical IssueSeverity = 2
+	// IssueSeverityUnknown means the issues could not be determined
+	IssueSeverityUnknown IssueSeverity = 3
+)
+
+// String returns the nagios status of the severity
+func (s IssueSeverity) String() string {
+	switch s {
+	case IssueSeverityNone:
+		return "OK"
+	case IssueSeverityWarning:
+		return "WARNING"
+	case IssueSeverityCritical:
+		return "CRITICAL"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// ShootIssue is an issue of a cluster which requires action
+type ShootIssue struct {
+	Severity IssueSeverity
+	Message  string
+}
+
+// ShootIssues returns the issues of the given cluster
+func ShootIssues(shoot *models.V1ClusterResponse) []ShootIssue {
+	var issues []ShootIssue
+
+	ms := shoot.Machines
+	ms = append(ms, shoot.Firewalls...)
+
+	for _, m := range ms {
+		severity, expires := imageExpires(m)
+		if expires != nil {
+			issues = append(issues, ShootIssue{Severity: severity, Message: expires.Error()})
+		}
+	}
+
+	if shoot.Firewalls != nil {
+		switch len(shoot.Firewalls) {
+		case 0:
+			issues = append(issues, ShootIssue{Severity: IssueSeverityCritical, Message: "Cluster has no firewall"})
+		case 1:
+		default:
+			issues = append(issues, ShootIssue{Severity: IssueSeverityWarning, Message: "Cluster has multiple firewalls, cluster requires manual administration"})
+		}
+	}
+
+	severity, expires := kubernetesExpires(shoot)
+	if expires != nil {
+		issues = append(issues, ShootIssue{Severity: severity, Message: expires.Error()})
+	}
+	mcmMigrated := false
+	for _, feature := range shoot.ControlPlaneFeatureGates {
+		if feature == "machineControllerManagerOOT" {
+			mcmMigrated = true
+			break
+		}
+	}
+	if !mcmMigrated {
+		issues = append(issues, ShootIssue{Severity: IssueSeverityWarning, Message: "Cluster requires migration to out-of-tree machine-controller-manager, please enable via shoot spec"})
+	}
+
+	return issues
+}
+
+// ShootIssuesSeverity returns the highest severity of the issues of the given clusters
+func ShootIssuesSeverity(shoots []*models.V1ClusterResponse) IssueSeverity {
+	severity := IssueSeverityNone
+	for _, shoot := range shoots {
+		for _, issue := range ShootIssues(shoot) {
+			if issue.Severity > severity {
+				severity = issue.Severity
+			}
+		}
+	}
+	return severity
+}
+
+// PrintShootIssuesNagios prints the issues of the given clusters in the output format of a nagios plugin
+// and returns the severity which is the exit code of the plugin
+func PrintShootIssuesNagios(w io.Writer, shoots []*models.V1ClusterResponse) IssueSeverity {
+	severity := IssueSeverityNone
+	counts := map[IssueSeverity]int{}
+	var details []string
+	for _, shoot := range shoots {
+		for _, issue := range ShootIssues(shoot) {
+			counts[issue.Severity]++
+			if issue.Severity > severity {
+				severity = issue.Severity
+			}
+			details = append(details, fmt.Sprintf("%s: %s (%s): %s", issue.Severity, StrValue(shoot.Name), StrValue(shoot.ID), issue.Message))
+		}
+	}
+	sort.SliceStable(details, func(i, j int) bool {
+		return strings.HasPrefix(details[i], IssueSeverityCritical.String()) && !strings.HasPrefix(details[j], IssueSeverityCritical.String())
+	})
+
+	fmt.Fprintf(w, "CLUSTER ISSUES %s - %d critical, %d warning issue(s) in %d cluster(s) | critical=%d;;;0 warning=%d;;;0 clusters=%d;;;0\n",
+		severity, counts[IssueSeverityCritical], counts[IssueSeverityWarning], len(shoots),
+		counts[IssueSeverityCritical], counts[IssueSeverityWarning], len(shoots))
+	for _, d := range details {
+		fmt.Fprintln(w, d)
+	}
+	return severity
+}
+
+// PrintShootIssuesNagiosError prints the error which prevented the check of the issues in the output format of a nagios plugin
+// and returns the severity unknown which is the exit code of the plugin
+func PrintShootIssuesNagiosError(w io.Writer, err error) IssueSeverity {
+	fmt.Fprintf(w, "CLUSTER ISSUES %s - %s\n", IssueSeverityUnknown, err)
+	return IssueSeverityUnknown
+}
+
+// PrintShootIssuesOpenMetrics prints the number of issues and the condition states of the given clusters in the OpenMetrics text format
+func PrintShootIssuesOpenMetrics(w io.Writer, shoots []*models.V1ClusterResponse) {
+	fmt.Fprintln(w, "# TYPE cloudctl_cluster_issues gauge")
+	fmt.Fprintln(w, "# HELP cloudctl_cluster_issues Number of issues of the cluster by severity.")
+	for _, shoot := range shoots {
+		counts := map[IssueSeverity]int{}
+		for _, issue := range ShootIssues(shoot) {
+			counts[issue.Severity]++
+		}
+		for _, severity := range []IssueSeverity{IssueSeverityWarning, IssueSeverityCritical} {
+			fmt.Fprintf(w, "cloudctl_cluster_issues{%s,severity=\"%s\"} %d\n", shootMetricLabels(shoot), strings.ToLower(severity.String()), counts[severity])
+		}
+	}
+
+	fmt.Fprintln(w, "# TYPE cloudctl_cluster_condition gauge")
+	fmt.Fprintln(w, "# HELP cloudctl_cluster_condition State of the conditions of the cluster, 1 for the current status of the condition.")
+	for _, shoot := range shoots {
+		if shoot.Status == nil {
+			continue
+		}
+		for _, c := range shoot.Status.Conditions {
+			for _, status := range []string{"True", "False", "Unknown"} {
+				value := 0
+				if StrValue(c.Status) == status {
+					value = 1
+				}
+				fmt.Fprintf(w, "cloudctl_cluster_condition{%s,condition=\"%s\",status=\"%s\"} %d\n", shootMetricLabels(shoot), metricLabelValue(StrValue(c.Type)), status, value)
+			}
+		}
+	}
+	fmt.Fprintln(w, "# EOF")
+}
+
+func shootMetricLabels(shoot *models.V1ClusterResponse) string {
+	return fmt.Sprintf("id=\"%s\",name=\"%s\",tenant=\"%s\",project=\"%s\",partition=\"%s\"",
+		metricLabelValue(StrValue(shoot.ID)), metricLabelValue(StrValue(shoot.Name)), metricLabelValue(StrValue(shoot.Tenant)),
+		metricLabelValue(StrValue(shoot.ProjectID)), metricLabelValue(StrValue(shoot.PartitionID)))
+}
+
+// metricLabelValue escapes a label value of the OpenMetrics text format
+func metricLabelValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"text/template"
 
@@ -22,6 +23,7 @@ type (
 	}
 	TablePrinter struct {
 		table       *tablewriter.Table
+		out         io.Writer
 		wide        bool
 		order       string
 		noHeaders   bool
@@ -64,7 +66,7 @@ func (t *TablePrinter) render() {
 			if len(row) < 1 {
 				continue
 			}
-			fmt.Println(row[0])
+			fmt.Fprintln(t.out, row[0])
 		}
 		t.shortData = [][]string{}
 		t.wideData = [][]string{}
@@ -126,26 +128,32 @@ func NewPrinter(format, order, tpl string, noHeaders bool) (Printer, error) {
 	case "json":
 		printer = &JSONPrinter{}
 	case "table", "wide":
-		printer = newTablePrinter(format, order, noHeaders, nil)
+		printer = newTablePrinter(os.Stdout, format, order, noHeaders, nil)
 	case "template":
 		tmpl, err := template.New("").Parse(tpl)
 		if err != nil {
 			return nil, fmt.Errorf("template invalid:%w", err)
 		}
-		printer = newTablePrinter(format, order, true, tmpl)
+		printer = newTablePrinter(os.Stdout, format, order, true, tmpl)
 	default:
 		return nil, fmt.Errorf("unknown format:%s", format)
 	}
 	return printer, nil
 }
 
-func newTablePrinter(format, order string, noHeaders bool, template *template.Template) TablePrinter {
+// NewTablePrinterTo returns a table printer which writes to the given writer instead of stdout
+func NewTablePrinterTo(w io.Writer, format, order string) Printer {
+	return newTablePrinter(w, format, order, false, nil)
+}
+
+func newTablePrinter(w io.Writer, format, order string, noHeaders bool, template *template.Template) TablePrinter {
 	tp := TablePrinter{
+		out:       w,
 		wide:      false,
 		order:     order,
 		noHeaders: noHeaders,
 	}
-	table := tablewriter.NewWriter(os.Stdout)
+	table := tablewriter.NewWriter(w)
 	if format == "wide" {
 		tp.wide = true
 	}
@@ -198,6 +206,22 @@ func (t TablePrinter) Print(data interface{}) error {
 		ShootLastErrorsTablePrinter{t}.Print(d)
 	case *models.V1beta1LastOperation:
 		ShootLastOperationTablePrinter{t}.Print(d)
+	case []*models.V1Worker:
+		WorkerTablePrinter{t}.Print(d)
+	case ClusterChanges:
+		ClusterChangesTablePrinter{t}.Print(d)
+	case MachineExecResults:
+		MachineExecResultTablePrinter{t}.Print(d)
+	case ClusterOperationResults:
+		ClusterOperationResultTablePrinter{t}.Print(d)
+	case ClusterUpgradePlans:
+		ClusterUpgradePlanTablePrinter{t}.Print(d)
+	case ClusterInputs:
+		ClusterInputsTablePrinter{t}.Print(d)
+	case *ClusterCostEstimate:
+		ClusterCostEstimateTablePrinter{t}.Print(d)
+	case ClusterTemplates:
+		ClusterTemplateTablePrinter{t}.Print(d)
 	case *models.V1ProjectResponse:
 		ProjectTablePrinter{t}.Print([]*models.V1ProjectResponse{d})
 	case []*models.V1ProjectResponse:
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/fatih/color"
 	"github.com/fi-ts/cloud-go/api/models"
 	"github.com/fi-ts/cloudctl/cmd/helper"
 	"github.com/gardener/gardener/pkg/apis/core/v1beta1"
@@ -33,6 +34,44 @@ type (
 	ShootLastOperationTablePrinter struct {
 		TablePrinter
 	}
+
+	// WorkerTablePrinter print the worker groups of a Shoot Cluster in a Table
+	WorkerTablePrinter struct {
+		TablePrinter
+	}
+
+	// MachineExecResult is the outcome of a command executed on a machine of a Shoot Cluster
+	MachineExecResult struct {
+		ID       string `json:"id"`
+		Name     string `json:"name"`
+		Type     string `json:"type"`
+		ExitCode int    `json:"exit_code"`
+		Error    string `json:"error,omitempty"`
+	}
+	// MachineExecResults are the outcomes of a command executed on machines of a Shoot Cluster
+	MachineExecResults []MachineExecResult
+
+	// MachineExecResultTablePrinter print the outcomes of a command executed on machines in a Table
+	MachineExecResultTablePrinter struct {
+		TablePrinter
+	}
+
+	// ClusterOperationResult is the outcome of an operation triggered on one of multiple selected clusters
+	ClusterOperationResult struct {
+		ID      string `json:"id"`
+		Name    string `json:"name"`
+		Project string `json:"project"`
+		// Operation is the last operation of the cluster after the operation was triggered
+		Operation string `json:"operation,omitempty"`
+		Error     string `json:"error,omitempty"`
+	}
+	// ClusterOperationResults are the outcomes of an operation triggered on multiple clusters
+	ClusterOperationResults []ClusterOperationResult
+
+	// ClusterOperationResultTablePrinter print the outcomes of an operation triggered on multiple clusters in a Table
+	ClusterOperationResultTablePrinter struct {
+		TablePrinter
+	}
 )
 
 const (
@@ -98,6 +137,43 @@ func (s ShootLastOperationTablePrinter) Print(data *models.V1beta1LastOperation)
 	s.render()
 }
 
+// Print worker groups as table
+func (s WorkerTablePrinter) Print(data []*models.V1Worker) {
+	s.shortHeader = []string{"Name", "Machine Type", "Image", "Runtime", "Min", "Max"}
+	s.wideHeader = []string{"Name", "Machine Type", "Image", "Runtime", "Min", "Max", "Max Surge", "Max Unavailable", "Health Timeout", "Drain Timeout"}
+	for _, w := range data {
+		image := ""
+		if w.MachineImage != nil {
+			image = StrValue(w.MachineImage.Name) + "-" + StrValue(w.MachineImage.Version)
+		}
+		cri := StrValue(w.CRI)
+		if cri == "" {
+			cri = "docker"
+		}
+		minimum := ""
+		if w.Minimum != nil {
+			minimum = fmt.Sprintf("%d", *w.Minimum)
+		}
+		maximum := ""
+		if w.Maximum != nil {
+			maximum = fmt.Sprintf("%d", *w.Maximum)
+		}
+		healthTimeout := ""
+		if w.HealthTimeout != 0 {
+			healthTimeout = time.Duration(w.HealthTimeout).String()
+		}
+		drainTimeout := ""
+		if w.DrainTimeout != 0 {
+			drainTimeout = time.Duration(w.DrainTimeout).String()
+		}
+		short := []string{StrValue(w.Name), StrValue(w.MachineType), image, cri, minimum, maximum}
+		wide := append(short, StrValue(w.MaxSurge), StrValue(w.MaxUnavailable), healthTimeout, drainTimeout)
+		s.addShortData(short, w)
+		s.addWideData(wide, w)
+	}
+	s.render()
+}
+
 // Print a Shoot as table
 func (s ShootTablePrinter) Print(data []*models.V1ClusterResponse) {
 	s.wideHeader = []string{"UID", "Name", "Version", "Partition", "Domain", "Operation", "Progress", "Api", "Control", "Nodes", "System", "Size", "Age", "Purpose", "Privileged", "Runtime", "Firewall", "Firewall Controller", "Egress IPs"}
@@ -132,8 +208,8 @@ func (s ShootIssuesTablePrinter) Print(data []*models.V1ClusterResponse) {
 	s.render()
 
 	if len(data) == 1 && len(issues) > 0 {
-		fmt.Println("\nIssues:")
-		printStringSlice(issues)
+		fmt.Fprintln(s.out, "\nIssues:")
+		printStringSlice(s.out, issues)
 	}
 }
 
@@ -142,40 +218,8 @@ func shootData(shoot *models.V1ClusterResponse, withIssues bool) ([]string, []st
 
 	maintainEmoji := ""
 	var issues []string
-
-	ms := shoot.Machines
-	ms = append(ms, shoot.Firewalls...)
-
-	for _, m := range ms {
-		expires := imageExpires(m)
-		if expires != nil {
-			issues = append(issues, expires.Error())
-		}
-	}
-
-	if shoot.Firewalls != nil {
-		switch len(shoot.Firewalls) {
-		case 0:
-			issues = append(issues, "Cluster has no firewall")
-		case 1:
-		default:
-			issues = append(issues, "Cluster has multiple firewalls, cluster requires manual administration")
-		}
-	}
-
-	expires := kubernetesExpires(shoot)
-	if expires != nil {
-		issues = append(issues, expires.Error())
-	}
-	mcmMigrated := false
-	for _, feature := range shoot.ControlPlaneFeatureGates {
-		if feature == "machineControllerManagerOOT" {
-			mcmMigrated = true
-			break
-		}
-	}
-	if !mcmMigrated {
-		issues = append(issues, "Cluster requires migration to out-of-tree machine-controller-manager, please enable via shoot spec")
+	for _, issue := range ShootIssues(shoot) {
+		issues = append(issues, issue.Message)
 	}
 
 	if len(issues) > 0 {
@@ -320,9 +364,9 @@ func newShootStats(status *models.V1beta1ShootStatus) *shootStats {
 	return &res
 }
 
-func imageExpires(m *models.ModelsV1MachineResponse) error {
+func imageExpires(m *models.ModelsV1MachineResponse) (IssueSeverity, error) {
 	if m.Allocation == nil || m.Allocation.Image == nil || m.Allocation.Image.ExpirationDate == nil {
-		return nil
+		return IssueSeverityNone, nil
 	}
 
 	host := *m.Allocation.Name
@@ -330,11 +374,11 @@ func imageExpires(m *models.ModelsV1MachineResponse) error {
 
 	t, err := time.Parse(time.RFC3339, *m.Allocation.Image.ExpirationDate)
 	if err != nil {
-		return fmt.Errorf("Image of %q has no valid expiration date: %s", host, imageID)
+		return IssueSeverityWarning, fmt.Errorf("Image of %q has no valid expiration date: %s", host, imageID)
 	}
 
 	if t.IsZero() {
-		return nil
+		return IssueSeverityNone, nil
 	}
 
 	viper.SetDefault("image-expiration-warning-days", ImageExpirationDaysDefault)
@@ -342,17 +386,17 @@ func imageExpires(m *models.ModelsV1MachineResponse) error {
 	expiresInHours := int(time.Until(t).Hours())
 
 	if expiresInHours <= 0 {
-		return fmt.Errorf("Image of %q has expired since %d day(s): %s", host, -expiresInHours/24, imageID)
+		return IssueSeverityCritical, fmt.Errorf("Image of %q has expired since %d day(s): %s", host, -expiresInHours/24, imageID)
 	} else if expiresInHours < expirationWarningDays*24 {
-		return fmt.Errorf("Image of %q expires in %d day(s): %s", host, expiresInHours/24, imageID)
+		return IssueSeverityWarning, fmt.Errorf("Image of %q expires in %d day(s): %s", host, expiresInHours/24, imageID)
 	}
 
-	return nil
+	return IssueSeverityNone, nil
 }
 
-func kubernetesExpires(shoot *models.V1ClusterResponse) error {
+func kubernetesExpires(shoot *models.V1ClusterResponse) (IssueSeverity, error) {
 	if shoot.Kubernetes == nil || shoot.Kubernetes.ExpirationDate == nil || time.Time(*shoot.Kubernetes.ExpirationDate).IsZero() {
-		return nil
+		return IssueSeverityNone, nil
 	}
 
 	viper.SetDefault("kubernetes-expiration-warning-days", ImageExpirationDaysDefault)
@@ -360,10 +404,48 @@ func kubernetesExpires(shoot *models.V1ClusterResponse) error {
 	expiresInHours := int(time.Until(time.Time(*shoot.Kubernetes.ExpirationDate)).Hours())
 
 	if expiresInHours <= 0 {
-		return fmt.Errorf("Kubernetes support has expired since %d day(s): %s", -expiresInHours/24, *shoot.Kubernetes.Version)
+		return IssueSeverityCritical, fmt.Errorf("Kubernetes support has expired since %d day(s): %s", -expiresInHours/24, *shoot.Kubernetes.Version)
 	} else if expiresInHours < expirationWarningDays*24 {
-		return fmt.Errorf("Kubernetes support expires in %d day(s): %s", expiresInHours/24, *shoot.Kubernetes.Version)
+		return IssueSeverityWarning, fmt.Errorf("Kubernetes support expires in %d day(s): %s", expiresInHours/24, *shoot.Kubernetes.Version)
 	}
 
-	return nil
+	return IssueSeverityNone, nil
+}
+
+func (s MachineExecResultTablePrinter) Print(data MachineExecResults) {
+	s.shortHeader = []string{"ID", "Name", "Type", "Exit Code", "Error"}
+	s.wideHeader = s.shortHeader
+	for _, r := range data {
+		exitCode := fmt.Sprintf("%d", r.ExitCode)
+		if r.ExitCode < 0 {
+			exitCode = "-"
+		}
+		row := []string{r.ID, r.Name, r.Type, exitCode, r.Error}
+		s.addShortData(row, r)
+		s.addWideData(row, r)
+	}
+	s.render()
+}
+
+func (s ClusterOperationResultTablePrinter) Print(data ClusterOperationResults) {
+	s.shortHeader = []string{"UID", "Name", "Project", "Result", "Operation"}
+	s.wideHeader = []string{"UID", "Name", "Project", "Result", "Operation", "Error"}
+	for _, r := range data {
+		result := color.GreenString("ok")
+		if r.Error != "" {
+			result = color.RedString("failed")
+		}
+		s.addShortData([]string{r.ID, r.Name, r.Project, result, r.Operation}, r)
+		s.addWideData([]string{r.ID, r.Name, r.Project, result, r.Operation, r.Error}, r)
+	}
+	s.render()
+}
+
+// LastOperationString returns the state, progress and type of the last operation of the cluster
+func LastOperationString(shoot *models.V1ClusterResponse) string {
+	if shoot == nil || shoot.Status == nil || shoot.Status.LastOperation == nil {
+		return ""
+	}
+	op := shoot.Status.LastOperation
+	return fmt.Sprintf("%s %s%% [%s]", StrValue(op.State), int32String(op.Progress), StrValue(op.Type))
 }
@@ -0,0 +1,224 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"github.com/fatih/color"
+	"github.com/fi-ts/cloud-go/api/models"
+)
+
+type (
+	// ClusterUpgradePlan are the steps to upgrade a cluster to the latest available kubernetes version
+	ClusterUpgradePlan struct {
+		ID      string `json:"id"`
+		Name    string `json:"name"`
+		Project string `json:"project"`
+		Version string `json:"version"`
+		// LatestPatch is the latest patch version of the current minor version, empty if the cluster already runs it
+		LatestPatch string `json:"latest_patch,omitempty"`
+		// NextMinor is the latest patch version of the next minor version, empty if there is none
+		NextMinor string `json:"next_minor,omitempty"`
+		// Steps are the versions to update to one after another, minor versions can not be skipped
+		Steps []string `json:"steps"`
+		// Deadline is the date when the support of the current version expires
+		Deadline      *time.Time            `json:"deadline,omitempty"`
+		MachineImages []MachineImageUpgrade `json:"machine_images,omitempty"`
+		Commands      []string              `json:"commands"`
+	}
+	// ClusterUpgradePlans are the upgrade plans of multiple clusters
+	ClusterUpgradePlans []ClusterUpgradePlan
+
+	// MachineImageUpgrade is the update of the machine image of a worker group running expiring images
+	MachineImageUpgrade struct {
+		Worker  string `json:"worker"`
+		Current string `json:"current"`
+		Latest  string `json:"latest"`
+		// Expiration is the earliest expiration date of the images of the machines of this worker group
+		Expiration *time.Time `json:"expiration,omitempty"`
+	}
+
+	// ClusterUpgradePlanTablePrinter print the upgrade plans of clusters in a Table
+	ClusterUpgradePlanTablePrinter struct {
+		TablePrinter
+	}
+)
+
+// NewClusterUpgradePlan returns the upgrade plan of the given cluster to the latest kubernetes version of the constraints,
+// if machineImages is true updates of worker groups with expiring machine images are planned as well.
+func NewClusterUpgradePlan(shoot *models.V1ClusterResponse, constraints *models.V1ShootConstraints, machineImages bool) (*ClusterUpgradePlan, error) {
+	id := StrValue(shoot.ID)
+	plan := &ClusterUpgradePlan{
+		ID:      id,
+		Name:    StrValue(shoot.Name),
+		Project: StrValue(shoot.ProjectID),
+		Steps:   []string{},
+	}
+	if shoot.Kubernetes == nil || shoot.Kubernetes.Version == nil {
+		return nil, fmt.Errorf("cluster %s has no kubernetes version", id)
+	}
+	plan.Version = *shoot.Kubernetes.Version
+	if shoot.Kubernetes.ExpirationDate != nil && !time.Time(*shoot.Kubernetes.ExpirationDate).IsZero() {
+		deadline := time.Time(*shoot.Kubernetes.ExpirationDate)
+		plan.Deadline = &deadline
+	}
+
+	current, err := semver.NewVersion(plan.Version)
+	if err != nil {
+		return nil, fmt.Errorf("version %s of cluster %s is invalid:%w", plan.Version, id, err)
+	}
+	// latest patch version per minor version
+	latest := map[int64]*semver.Version{}
+	for _, available := range constraints.KubernetesVersions {
+		v, err := semver.NewVersion(available)
+		if err != nil || v.Major() != current.Major() {
+			continue
+		}
+		if l, ok := latest[v.Minor()]; !ok || v.GreaterThan(l) {
+			latest[v.Minor()] = v
+		}
+	}
+
+	if l, ok := latest[current.Minor()]; ok && l.GreaterThan(current) {
+		plan.LatestPatch = l.Original()
+	}
+	if l, ok := latest[current.Minor()+1]; ok {
+		plan.NextMinor = l.Original()
+	}
+	for minor := current.Minor() + 1; ; minor++ {
+		l, ok := latest[minor]
+		if !ok {
+			break
+		}
+		plan.Steps = append(plan.Steps, l.Original())
+	}
+	if len(plan.Steps) == 0 && plan.LatestPatch != "" {
+		plan.Steps = append(plan.Steps, plan.LatestPatch)
+	}
+	for _, step := range plan.Steps {
+		plan.Commands = append(plan.Commands, fmt.Sprintf("cloudctl cluster update %s --version %s", id, step))
+	}
+
+	if machineImages {
+		plan.MachineImages = machineImageUpgrades(shoot, constraints)
+		for _, u := range plan.MachineImages {
+			plan.Commands = append(plan.Commands, fmt.Sprintf("cloudctl cluster update %s --workergroup %s --machineimage %s", id, u.Worker, u.Latest))
+		}
+	}
+
+	return plan, nil
+}
+
+// machineImageUpgrades returns the updates to the latest version of the same image for worker groups
+// which have machines running images that expire soon or are already expired
+func machineImageUpgrades(shoot *models.V1ClusterResponse, constraints *models.V1ShootConstraints) []MachineImageUpgrade {
+	// earliest expiration date of an expiring image per image id
+	expiring := map[string]time.Time{}
+	for _, m := range shoot.Machines {
+		severity, _ := imageExpires(m)
+		if severity == IssueSeverityNone || m.Allocation.Image.ID == nil {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, *m.Allocation.Image.ExpirationDate)
+		if err != nil {
+			continue
+		}
+		id := *m.Allocation.Image.ID
+		if e, ok := expiring[id]; !ok || t.Before(e) {
+			expiring[id] = t
+		}
+	}
+
+	var upgrades []MachineImageUpgrade
+	for _, w := range shoot.Workers {
+		if w.MachineImage == nil {
+			continue
+		}
+		current := machineImageString(w.MachineImage)
+		expiration, ok := expiring[current]
+		if !ok {
+			continue
+		}
+		latest := latestMachineImage(StrValue(w.MachineImage.Name), constraints.MachineImages)
+		if latest == nil || machineImageString(latest) == current || !newerImageVersion(StrValue(latest.Version), StrValue(w.MachineImage.Version)) {
+			continue
+		}
+		upgrades = append(upgrades, MachineImageUpgrade{
+			Worker:     StrValue(w.Name),
+			Current:    current,
+			Latest:     machineImageString(latest),
+			Expiration: &expiration,
+		})
+	}
+	return upgrades
+}
+
+func latestMachineImage(name string, images []*models.V1MachineImage) *models.V1MachineImage {
+	var latest *models.V1MachineImage
+	for _, image := range images {
+		if StrValue(image.Name) != name {
+			continue
+		}
+		if latest == nil || newerImageVersion(StrValue(image.Version), StrValue(latest.Version)) {
+			latest = image
+		}
+	}
+	return latest
+}
+
+// newerImageVersion returns true if version a is newer than version b,
+// versions which are not semantic versions are compared lexically
+func newerImageVersion(a, b string) bool {
+	va, errA := semver.NewVersion(a)
+	vb, errB := semver.NewVersion(b)
+	if errA != nil || errB != nil {
+		return a > b
+	}
+	return va.GreaterThan(vb)
+}
+
+// Print the upgrade plans of clusters, clusters with expired versions are highlighted
+func (s ClusterUpgradePlanTablePrinter) Print(data ClusterUpgradePlans) {
+	s.shortHeader = []string{"UID", "Name", "Version", "Latest Patch", "Next Minor", "Upgrade Path", "Deadline"}
+	s.wideHeader = []string{"UID", "Name", "Project", "Version", "Latest Patch", "Next Minor", "Upgrade Path", "Deadline", "Machine Images"}
+	sort.SliceStable(data, func(i, j int) bool {
+		return deadlineBefore(data[i].Deadline, data[j].Deadline)
+	})
+	for _, p := range data {
+		path := strings.Join(p.Steps, " → ")
+		if path == "" {
+			path = "up to date"
+		}
+		deadline := ""
+		if p.Deadline != nil {
+			deadline = p.Deadline.Format("2006-01-02")
+			until := time.Until(*p.Deadline)
+			if until <= 0 {
+				deadline = color.RedString(deadline + " (expired)")
+			} else {
+				deadline += " (" + humanizeDuration(until.Truncate(24*time.Hour)) + ")"
+			}
+		}
+		var images []string
+		for _, u := range p.MachineImages {
+			images = append(images, fmt.Sprintf("%s: %s → %s", u.Worker, u.Current, u.Latest))
+		}
+		s.addShortData([]string{p.ID, p.Name, p.Version, p.LatestPatch, p.NextMinor, path, deadline}, p)
+		s.addWideData([]string{p.ID, p.Name, p.Project, p.Version, p.LatestPatch, p.NextMinor, path, deadline, strings.Join(images, "\n")}, p)
+	}
+	s.render()
+}
+
+// deadlineBefore orders the earliest deadline first, plans without deadline last
+func deadlineBefore(a, b *time.Time) bool {
+	if a == nil {
+		return false
+	}
+	if b == nil {
+		return true
+	}
+	return a.Before(*b)
+}
@@ -49,13 +49,31 @@ var (
 			initPrinter()
 		},
 		SilenceUsage: true,
+		// errors are printed by Execute, which does not print an exitCodeError
+		SilenceErrors: true,
 	}
 )
 
+// exitCodeError makes cloudctl exit with the given code without printing an error,
+// e.g. for commands which report a state by the exit code
+type exitCodeError int
+
+func (e exitCodeError) Error() string {
+	return fmt.Sprintf("exit code %d", int(e))
+}
+
 // Execute is the entrypoint of the cloudctl application
 func Execute() {
-	err := rootCmd.Execute()
+	cmd, err := rootCmd.ExecuteC()
 	if err != nil {
+		var exitCode exitCodeError
+		if errors.As(err, &exitCode) {
+			os.Exit(int(exitCode))
+		}
+		fmt.Fprintln(os.Stderr, "Error:", err.Error())
+		if cmd == rootCmd {
+			fmt.Fprintf(os.Stderr, "Run '%v --help' for usage.\n", cmd.CommandPath())
+		}
 		if viper.GetBool("debug") {
 			st := errors.WithStack(err)
 			fmt.Printf("%+v", st)
@@ -84,6 +102,8 @@ func init() {
 	rootCmd.AddCommand(updateCmd)
 	rootCmd.AddCommand(loginCmd)
 	rootCmd.AddCommand(whoamiCmd)
+	rootCmd.AddCommand(tokenCmd)
+	rootCmd.AddCommand(dashboardCmd)
 	rootCmd.AddCommand(projectCmd)
 	rootCmd.AddCommand(tenantCmd)
 	rootCmd.AddCommand(contextCmd)
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/fi-ts/cloudctl/cmd/helper"
+	"github.com/metal-stack/metal-lib/auth"
+	"github.com/metal-stack/metal-lib/jwt/sec"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/oauth2"
+)
+
+// tokenRefreshThreshold is the remaining validity below which the id token gets refreshed
+const tokenRefreshThreshold = time.Minute
+
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "print a kubernetes exec credential of the current user",
+	Long: `prints the id token of the current user as client.authentication.k8s.io ExecCredential, the token is refreshed with the stored refresh token if it expires.
+this command is meant to be called by kubectl as exec credential plugin, see cloudctl cluster kubeconfig --exec.
+the token is taken from the given --context, so kubectl keeps the environment of the kubeconfig if the current context changes.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return token()
+	},
+	PreRun: bindPFlags,
+}
+
+func init() {
+	tokenCmd.Flags().String("context", "", "cloudctl context of the token, defaults to the current context.")
+	tokenCmd.RegisterFlagCompletionFunc("context", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return contextListCompletion()
+	})
+}
+
+type execCredential struct {
+	APIVersion string               `json:"apiVersion"`
+	Kind       string               `json:"kind"`
+	Status     execCredentialStatus `json:"status"`
+}
+
+type execCredentialStatus struct {
+	Token               string    `json:"token"`
+	ExpirationTimestamp time.Time `json:"expirationTimestamp"`
+}
+
+func token() error {
+	kubeconfig := viper.GetString("kubeConfig")
+	var (
+		authContext *auth.AuthContext
+		err         error
+	)
+	if ctxName := viper.GetString("context"); ctxName != "" {
+		authContext, err = getAuthContextOf(kubeconfig, ctxName)
+	} else {
+		authContext, err = getAuthContext(kubeconfig)
+	}
+	if err != nil {
+		return err
+	}
+
+	idToken := authContext.IDToken
+	expiresAt, err := tokenExpiry(idToken)
+	if err != nil {
+		return err
+	}
+
+	if time.Until(expiresAt) < tokenRefreshThreshold {
+		idToken, err = refreshIDToken(kubeconfig, authContext)
+		if err != nil {
+			return err
+		}
+		expiresAt, err = tokenExpiry(idToken)
+		if err != nil {
+			return err
+		}
+	}
+
+	credential := execCredential{
+		APIVersion: helper.ExecCredentialAPIVersion,
+		Kind:       "ExecCredential",
+		Status: execCredentialStatus{
+			Token:               idToken,
+			ExpirationTimestamp: expiresAt.UTC(),
+		},
+	}
+	return json.NewEncoder(os.Stdout).Encode(credential)
+}
+
+func tokenExpiry(idToken string) (time.Time, error) {
+	_, claims, err := sec.ParseTokenUnvalidatedUnfiltered(idToken)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(claims.ExpiresAt, 0), nil
+}
+
+// refreshIDToken fetches a new id token with the refresh token of the user of the given auth context
+// and stores both tokens in the kubeconfig
+func refreshIDToken(kubeconfig string, authContext *auth.AuthContext) (string, error) {
+	cfg, filename, _, err := auth.LoadKubeConfig(kubeconfig)
+	if err != nil {
+		return "", err
+	}
+	refreshToken := helper.KubeconfigRefreshToken(cfg, authContext.User)
+	if refreshToken == "" {
+		return "", fmt.Errorf("token of user %s is expired and no refresh token is stored, please login again", authContext.User)
+	}
+
+	reqCtx := context.Background()
+	if authContext.IssuerCA != "" {
+		client, err := issuerClient(authContext.IssuerCA)
+		if err != nil {
+			return "", err
+		}
+		reqCtx = oidc.ClientContext(reqCtx, client)
+	}
+
+	provider, err := oidc.NewProvider(reqCtx, authContext.IssuerURL)
+	if err != nil {
+		return "", fmt.Errorf("unable to discover issuer:%s %w", authContext.IssuerURL, err)
+	}
+	config := oauth2.Config{
+		ClientID:     authContext.ClientID,
+		ClientSecret: authContext.ClientSecret,
+		Endpoint:     provider.Endpoint(),
+	}
+	t, err := config.TokenSource(reqCtx, &oauth2.Token{RefreshToken: refreshToken}).Token()
+	if err != nil {
+		return "", fmt.Errorf("unable to refresh token, please login again: %w", err)
+	}
+	idToken, ok := t.Extra("id_token").(string)
+	if !ok || idToken == "" {
+		return "", fmt.Errorf("issuer:%s returned no id token on refresh", authContext.IssuerURL)
+	}
+
+	err = helper.SetKubeconfigTokens(cfg, authContext.User, idToken, t.RefreshToken)
+	if err != nil {
+		return "", err
+	}
+	content, err := auth.EncodeKubeconfig(cfg)
+	if err != nil {
+		return "", err
+	}
+	err = writePrivateFile(filename, content.Bytes())
+	if err != nil {
+		return "", err
+	}
+
+	return idToken, nil
+}
+
+// issuerClient returns a http client trusting the certificate authority in the given file
+func issuerClient(caFile string) (*http.Client, error) {
+	ca, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read issuer certificate authority:%s %w", caFile, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("no certificates found in issuer certificate authority:%s", caFile)
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs:    pool,
+				MinVersion: tls.VersionTLS12,
+			},
+		},
+	}, nil
+}
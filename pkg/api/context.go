@@ -1,10 +1,14 @@
 package api
 
+import "github.com/fi-ts/cloud-go/api/models"
+
 // Contexts contains all configuration contexts of cloudctl
 type Contexts struct {
 	CurrentContext  string `yaml:"current"`
 	PreviousContext string `yaml:"previous"`
 	Contexts        map[string]Context
+	// ClusterTemplates are partial cluster create requests which can be used in all contexts
+	ClusterTemplates map[string]models.V1ClusterCreateRequest `yaml:"cluster-templates,omitempty"`
 }
 
 // Context configure cloudctl behaviour
@@ -16,4 +20,6 @@ type Context struct {
 	ClientID     string  `yaml:"client_id"`
 	ClientSecret string  `yaml:"client_secret"`
 	HMAC         *string `yaml:"hmac"`
+	// ClusterTemplates are partial cluster create requests of this context, they take precedence over global templates with the same name
+	ClusterTemplates map[string]models.V1ClusterCreateRequest `yaml:"cluster-templates,omitempty"`
 }